@@ -3,7 +3,10 @@ package auth
 import (
 	"bytes"
 	"encoding/json"
+	"fmt"
+	"math"
 	"net/http"
+	"time"
 
 	"iniciador-sdk/iniciador/utils"
 )
@@ -13,9 +16,45 @@ type AuthOutput struct {
 }
 
 type AuthInterfaceOutput struct {
-	AccessToken  string `json:"accessToken"`
-	InterfaceURL string `json:"interfaceURL"`
-	PaymentID    string `json:"paymentId"`
+	AccessToken  string           `json:"accessToken"`
+	InterfaceURL string           `json:"interfaceURL"`
+	PaymentID    string           `json:"paymentId"`
+	Claims       *utils.TokenData `json:"-"`
+}
+
+// InterfaceSession prefills the payment the hosted interface opens with.
+// Every field is optional; amounts are in cents.
+type InterfaceSession struct {
+	Amount             float64                `json:"amount,omitempty"`
+	Creditor           *utils.Creditor        `json:"creditor,omitempty"`
+	Description        string                 `json:"description,omitempty"`
+	ExternalID         string                 `json:"externalId,omitempty"`
+	PaymentMethods     []utils.PaymentMethod  `json:"paymentMethods,omitempty"`
+	RedirectURL        string                 `json:"redirectURL,omitempty"`
+	RedirectOnErrorURL string                 `json:"redirectOnErrorURL,omitempty"`
+	Metadata           map[string]interface{} `json:"metadata,omitempty"`
+	ExpiresAt          utils.Timestamp        `json:"expiresAt,omitempty"`
+}
+
+func (s *InterfaceSession) Validate() error {
+	if s.Amount < 0 || s.Amount != math.Trunc(s.Amount) {
+		return fmt.Errorf("amount must be a positive amount in cents, got %v", s.Amount)
+	}
+	for _, method := range s.PaymentMethods {
+		if !method.IsValid() {
+			return fmt.Errorf("invalid payment method %q", method)
+		}
+	}
+	if !s.ExpiresAt.IsZero() && !s.ExpiresAt.After(time.Now()) {
+		return fmt.Errorf("expiresAt %s is in the past", s.ExpiresAt.Format(time.RFC3339))
+	}
+	return nil
+}
+
+type authInterfaceRequest struct {
+	ClientID     string `json:"clientId"`
+	ClientSecret string `json:"clientSecret"`
+	*InterfaceSession
 }
 
 type AuthClient struct {
@@ -65,12 +104,28 @@ func (c *AuthClient) Auth() (*AuthOutput, error) {
 	return &authOutput, nil
 }
 
+// AuthInterface authenticates with the hosted interface. The returned output
+// carries the claims of the access token, when it is a JWT.
 func (c *AuthClient) AuthInterface() (*AuthInterfaceOutput, error) {
-	requestBody := map[string]interface{}{
-		"clientId":     c.ClientID,
-		"clientSecret": c.ClientSecret,
+	return c.AuthInterfaceWithSession(nil)
+}
+
+// AuthInterfaceWithSession authenticates with the hosted interface like
+// AuthInterface, prefilling the payment with session so the customer does not
+// have to type it.
+func (c *AuthClient) AuthInterfaceWithSession(session *InterfaceSession) (*AuthInterfaceOutput, error) {
+	requestBody := &authInterfaceRequest{
+		ClientID:     c.ClientID,
+		ClientSecret: c.ClientSecret,
 	}
-	requestBodyBytes, err := json.Marshal(requestBody)
+	if session != nil {
+		err := session.Validate()
+		if err != nil {
+			return nil, err
+		}
+		requestBody.InterfaceSession = session
+	}
+	requestBodyBytes, err := utils.MarshalWithoutEmptyFields(requestBody)
 	if err != nil {
 		return nil, err
 	}
@@ -91,5 +146,11 @@ func (c *AuthClient) AuthInterface() (*AuthInterfaceOutput, error) {
 		return nil, err
 	}
 
+	// Tokens that are not JWTs are returned as they are, without claims.
+	claims, err := utils.ParseTokenData(authInterfaceOutput.AccessToken)
+	if err == nil {
+		authInterfaceOutput.Claims = claims
+	}
+
 	return &authInterfaceOutput, nil
 }
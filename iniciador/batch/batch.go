@@ -0,0 +1,229 @@
+package batch
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"iniciador-sdk/iniciador/auth"
+	"iniciador-sdk/iniciador/payments"
+	"iniciador-sdk/iniciador/utils"
+)
+
+const DefaultWorkers = 4
+
+// ErrInvalidBatch is returned by Submit when at least one payment fails
+// validation. Nothing is sent in that case.
+var ErrInvalidBatch = errors.New("batch contains invalid payments")
+
+type ItemStatus string
+
+const (
+	// Created means the API accepted the payment.
+	Created ItemStatus = "CREATED"
+	// Rejected means the API refused the payment or the request failed.
+	Rejected ItemStatus = "REJECTED"
+	// Invalid means the payment failed local validation.
+	Invalid ItemStatus = "INVALID"
+	// Skipped means the payment was not sent, because the batch was invalid
+	// or cancelled before reaching it.
+	Skipped ItemStatus = "SKIPPED"
+)
+
+// Item is a payment of the batch. Key identifies the item across runs and is
+// sent as its idempotency key; when empty, the payment ExternalID is used.
+type Item struct {
+	Key     string
+	Payment *payments.PaymentInitiationPayload
+}
+
+type ItemResult struct {
+	Key      string                             `json:"key"`
+	Status   ItemStatus                         `json:"status"`
+	Payment  *payments.PaymentInitiationPayload `json:"payment,omitempty"`
+	Error    string                             `json:"error,omitempty"`
+	APIError *utils.APIError                    `json:"apiError,omitempty"`
+}
+
+// Report holds one result per item, in the order the items were given. It
+// can be stored as JSON and passed back through Options.Previous to resume.
+type Report struct {
+	Items []ItemResult `json:"items"`
+}
+
+func (r *Report) withStatus(status ItemStatus) []ItemResult {
+	var results []ItemResult
+	for _, item := range r.Items {
+		if item.Status == status {
+			results = append(results, item)
+		}
+	}
+	return results
+}
+
+func (r *Report) Created() []ItemResult {
+	return r.withStatus(Created)
+}
+
+func (r *Report) Rejected() []ItemResult {
+	return r.withStatus(Rejected)
+}
+
+func (r *Report) Invalid() []ItemResult {
+	return r.withStatus(Invalid)
+}
+
+func (r *Report) Skipped() []ItemResult {
+	return r.withStatus(Skipped)
+}
+
+type Options struct {
+	// Workers is the number of payments sent concurrently. Defaults to DefaultWorkers.
+	Workers int
+	// RatePerSecond caps how many payments are sent per second. Zero means no limit.
+	RatePerSecond float64
+	// Previous is the report of an earlier run. Items it reports as created
+	// are not sent again.
+	Previous *Report
+	// OnResult is called serially as soon as each item is settled, so
+	// results can be persisted to resume after a crash.
+	OnResult func(ItemResult)
+}
+
+// Submit validates every payment and, only if all of them are valid, sends
+// them with a bounded pool of workers. Each payment carries its item key as
+// idempotency key, so resubmitting a batch never duplicates payments.
+func Submit(ctx context.Context, accessToken string, items []Item, authClient *auth.AuthClient, options *Options) (*Report, error) {
+	if options == nil {
+		options = &Options{}
+	}
+	workers := options.Workers
+	if workers <= 0 {
+		workers = DefaultWorkers
+	}
+
+	report := &Report{Items: make([]ItemResult, len(items))}
+	previous := make(map[string]ItemResult)
+	if options.Previous != nil {
+		for _, result := range options.Previous.Items {
+			previous[result.Key] = result
+		}
+	}
+
+	isValid := true
+	seen := make(map[string]bool)
+	for i, item := range items {
+		key := itemKey(item)
+		report.Items[i] = ItemResult{Key: key, Status: Skipped}
+
+		var err error
+		switch {
+		case key == "":
+			err = fmt.Errorf("item %d has no key or externalId", i)
+		case seen[key]:
+			err = fmt.Errorf("item key %q is repeated", key)
+		case item.Payment == nil:
+			err = fmt.Errorf("item %q has no payment", key)
+		default:
+			err = item.Payment.Validate()
+		}
+		seen[key] = true
+
+		if err != nil {
+			isValid = false
+			report.Items[i].Status = Invalid
+			report.Items[i].Error = err.Error()
+		}
+	}
+	if !isValid {
+		return report, ErrInvalidBatch
+	}
+
+	var mutex sync.Mutex
+	settle := func(i int, result ItemResult) {
+		mutex.Lock()
+		defer mutex.Unlock()
+		report.Items[i] = result
+		if options.OnResult != nil {
+			options.OnResult(result)
+		}
+	}
+
+	var limiter <-chan time.Time
+	if options.RatePerSecond > 0 {
+		ticker := time.NewTicker(time.Duration(float64(time.Second) / options.RatePerSecond))
+		defer ticker.Stop()
+		limiter = ticker.C
+	}
+
+	pending := make(chan int)
+	var wg sync.WaitGroup
+	for w := 0; w < workers; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range pending {
+				settle(i, send(accessToken, report.Items[i].Key, items[i].Payment, authClient))
+			}
+		}()
+	}
+
+dispatch:
+	for i := range items {
+		if ctx.Err() != nil {
+			break
+		}
+		if result, ok := previous[report.Items[i].Key]; ok && result.Status == Created {
+			settle(i, result)
+			continue
+		}
+
+		if limiter != nil {
+			select {
+			case <-limiter:
+			case <-ctx.Done():
+				break dispatch
+			}
+		}
+		select {
+		case pending <- i:
+		case <-ctx.Done():
+			break dispatch
+		}
+	}
+	close(pending)
+	wg.Wait()
+
+	return report, ctx.Err()
+}
+
+func send(accessToken, key string, payment *payments.PaymentInitiationPayload, authClient *auth.AuthClient) ItemResult {
+	result := ItemResult{Key: key}
+
+	created, err := payments.Send(accessToken, payment, authClient, payments.WithIdempotencyKey(key))
+	if err != nil {
+		result.Status = Rejected
+		result.Error = err.Error()
+		var apiError *utils.APIError
+		if errors.As(err, &apiError) {
+			result.APIError = apiError
+		}
+		return result
+	}
+
+	result.Status = Created
+	result.Payment = created
+	return result
+}
+
+func itemKey(item Item) string {
+	if item.Key != "" {
+		return item.Key
+	}
+	if item.Payment != nil {
+		return item.Payment.ExternalID
+	}
+	return ""
+}
@@ -0,0 +1,293 @@
+package callback
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"sync"
+	"time"
+
+	"iniciador-sdk/iniciador/auth"
+	"iniciador-sdk/iniciador/payments"
+)
+
+const DefaultSessionTTL = 30 * time.Minute
+
+var (
+	// ErrUnknownState is reported when the redirect carries a state that was
+	// never issued or was already used, as happens with replayed redirects.
+	ErrUnknownState = errors.New("unknown or already used state")
+	// ErrSessionExpired is reported when the redirect arrives after the
+	// session expired.
+	ErrSessionExpired = errors.New("session expired")
+	// ErrTampered is reported when the redirect parameters or the fetched
+	// payment do not match the session.
+	ErrTampered = errors.New("redirect does not match the session")
+	// ErrPaymentPending is reported to OnFailure when the payment is still
+	// not final after polling and no OnPending callback is set.
+	ErrPaymentPending = errors.New("payment is still pending")
+)
+
+// Session links a whitelabel checkout to the redirect that ends it. State is
+// an unguessable value added to the redirect URLs.
+type Session struct {
+	State       string    `json:"state"`
+	AccessToken string    `json:"accessToken"`
+	PaymentID   string    `json:"paymentId"`
+	ExternalID  string    `json:"externalId,omitempty"`
+	ExpiresAt   time.Time `json:"expiresAt"`
+}
+
+// SessionStore keeps sessions until their redirect arrives. Consume must
+// return each session at most once, which is what prevents replays.
+type SessionStore interface {
+	Save(session *Session) error
+	// Get returns the session of state without consuming it, or
+	// ErrUnknownState.
+	Get(state string) (*Session, error)
+	// Consume returns and removes the session of state, or ErrUnknownState.
+	Consume(state string) (*Session, error)
+}
+
+// MemoryStore is a SessionStore for a single process. Save drops the
+// sessions that expired, so abandoned checkouts do not accumulate; until then
+// a late redirect still finds its session and reports ErrSessionExpired.
+type MemoryStore struct {
+	mutex    sync.Mutex
+	sessions map[string]*Session
+}
+
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{sessions: make(map[string]*Session)}
+}
+
+func (s *MemoryStore) Save(session *Session) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	now := time.Now()
+	for state, saved := range s.sessions {
+		if now.After(saved.ExpiresAt) {
+			delete(s.sessions, state)
+		}
+	}
+
+	s.sessions[session.State] = session
+	return nil
+}
+
+func (s *MemoryStore) Get(state string) (*Session, error) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	session, ok := s.sessions[state]
+	if !ok {
+		return nil, ErrUnknownState
+	}
+	return session, nil
+}
+
+func (s *MemoryStore) Consume(state string) (*Session, error) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	session, ok := s.sessions[state]
+	if !ok {
+		return nil, ErrUnknownState
+	}
+	delete(s.sessions, state)
+	return session, nil
+}
+
+// NewSession starts a session with a random state, expiring after ttl
+// (DefaultSessionTTL when zero). Add the state to the redirect URLs with
+// WithState before calling AuthInterface, then call Save with its output.
+func NewSession(externalID string, ttl time.Duration) (*Session, error) {
+	if ttl == 0 {
+		ttl = DefaultSessionTTL
+	}
+
+	stateBytes := make([]byte, 32)
+	_, err := rand.Read(stateBytes)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Session{
+		State:      hex.EncodeToString(stateBytes),
+		ExternalID: externalID,
+		ExpiresAt:  time.Now().Add(ttl),
+	}, nil
+}
+
+// Save links the session to the payment created by AuthInterface and stores it.
+func (s *Session) Save(store SessionStore, output *auth.AuthInterfaceOutput) error {
+	s.AccessToken = output.AccessToken
+	s.PaymentID = output.PaymentID
+	return store.Save(s)
+}
+
+// WithState adds the state query parameter to redirectURL.
+func WithState(redirectURL, state string) (string, error) {
+	parsed, err := url.Parse(redirectURL)
+	if err != nil {
+		return "", err
+	}
+	query := parsed.Query()
+	query.Set("state", state)
+	parsed.RawQuery = query.Encode()
+	return parsed.String(), nil
+}
+
+type Result struct {
+	Session *Session
+	Status  *payments.PaymentStatusPayload
+}
+
+// Failure describes a redirect that did not end in a successful payment.
+// Session and Status are nil when the failure happened before they were known.
+type Failure struct {
+	Err     error
+	Query   url.Values
+	Session *Session
+	Status  *payments.PaymentStatusPayload
+}
+
+// Handler handles the redirects back from the hosted interface, to both
+// RedirectURL and RedirectOnErrorURL. It checks the paymentId and externalId
+// parameters against the session of the state parameter, consumes the
+// session, fetches the payment status and calls the callback matching the
+// outcome.
+type Handler struct {
+	AuthClient *auth.AuthClient
+	Store      SessionStore
+
+	// PollInterval and PollTimeout control how long the handler waits for a
+	// payment that is not final yet. Without a timeout the status is fetched once.
+	PollInterval time.Duration
+	PollTimeout  time.Duration
+
+	// OnSuccess is called for completed and scheduled payments.
+	OnSuccess func(w http.ResponseWriter, r *http.Request, result *Result)
+	// OnPending is called when the payment is still not final after polling.
+	// When nil, OnFailure is called with ErrPaymentPending.
+	OnPending func(w http.ResponseWriter, r *http.Request, result *Result)
+	// OnFailure is called for failed payments and invalid redirects.
+	OnFailure func(w http.ResponseWriter, r *http.Request, failure *Failure)
+}
+
+func (h *Handler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+	failure := &Failure{Query: query}
+
+	// Tampered redirects are refused before the session is consumed, so
+	// they cannot keep the legitimate redirect from completing.
+	state := query.Get("state")
+	session, err := h.Store.Get(state)
+	if err != nil {
+		failure.Err = err
+		h.fail(w, r, failure)
+		return
+	}
+	if !matches(query.Get("paymentId"), session.PaymentID) || !matches(query.Get("externalId"), session.ExternalID) {
+		failure.Err = ErrTampered
+		h.fail(w, r, failure)
+		return
+	}
+
+	session, err = h.Store.Consume(state)
+	if err != nil {
+		failure.Err = err
+		h.fail(w, r, failure)
+		return
+	}
+	failure.Session = session
+
+	if time.Now().After(session.ExpiresAt) {
+		failure.Err = ErrSessionExpired
+		h.fail(w, r, failure)
+		return
+	}
+
+	status, err := h.fetchStatus(r.Context(), session)
+	if err != nil {
+		failure.Err = err
+		h.fail(w, r, failure)
+		return
+	}
+	failure.Status = status
+
+	if status.ID != session.PaymentID {
+		failure.Err = ErrTampered
+		h.fail(w, r, failure)
+		return
+	}
+
+	result := &Result{Session: session, Status: status}
+	paymentStatus := payments.PaymentInitiationStatus(status.Status)
+	switch {
+	case paymentStatus == payments.PaymentCompleted || paymentStatus == payments.PaymentScheduled:
+		if h.OnSuccess != nil {
+			h.OnSuccess(w, r, result)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+	case paymentStatus.IsFailure():
+		failure.Err = fmt.Errorf("payment %s ended with status %s", status.ID, status.Status)
+		h.fail(w, r, failure)
+	case h.OnPending != nil:
+		h.OnPending(w, r, result)
+	default:
+		failure.Err = ErrPaymentPending
+		h.fail(w, r, failure)
+	}
+}
+
+// fetchStatus polls the payment status until it is final, scheduled, the
+// poll timeout is reached or ctx is done, as when the client disconnects.
+func (h *Handler) fetchStatus(ctx context.Context, session *Session) (*payments.PaymentStatusPayload, error) {
+	interval := h.PollInterval
+	if interval <= 0 {
+		interval = time.Second
+	}
+	deadline := time.Now().Add(h.PollTimeout)
+
+	for {
+		status, err := payments.Status(session.AccessToken, h.AuthClient)
+		if err != nil {
+			return nil, err
+		}
+		paymentStatus := payments.PaymentInitiationStatus(status.Status)
+		if paymentStatus.IsFinal() || paymentStatus == payments.PaymentScheduled || !time.Now().Add(interval).Before(deadline) {
+			return status, nil
+		}
+
+		timer := time.NewTimer(interval)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return nil, ctx.Err()
+		case <-timer.C:
+		}
+	}
+}
+
+func (h *Handler) fail(w http.ResponseWriter, r *http.Request, failure *Failure) {
+	if h.OnFailure != nil {
+		h.OnFailure(w, r, failure)
+		return
+	}
+	http.Error(w, failure.Err.Error(), http.StatusBadRequest)
+}
+
+// matches reports whether a redirect parameter agrees with the session value.
+// Absent parameters are not checked.
+func matches(parameter, expected string) bool {
+	if parameter == "" {
+		return true
+	}
+	return subtle.ConstantTimeCompare([]byte(parameter), []byte(expected)) == 1
+}
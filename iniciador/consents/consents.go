@@ -0,0 +1,158 @@
+package consents
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+	"time"
+
+	"iniciador-sdk/iniciador/auth"
+	"iniciador-sdk/iniciador/payments"
+	"iniciador-sdk/iniciador/utils"
+)
+
+type ConsentStatus string
+
+const (
+	AwaitingAuthorization ConsentStatus = "AWAITING_AUTHORIZATION"
+	Authorized            ConsentStatus = "AUTHORIZED"
+	Rejected              ConsentStatus = "REJECTED"
+	Consumed              ConsentStatus = "CONSUMED"
+	Revoked               ConsentStatus = "REVOKED"
+)
+
+type RejectionReason struct {
+	Code   string `json:"code"`
+	Detail string `json:"detail"`
+}
+
+// ConsentPayload is the authorization the user gives in their bank for a
+// payment, referenced by the ConsentID of the payment. Amount is in cents.
+type ConsentPayload struct {
+	utils.UnknownFields `json:"-"`
+
+	ID                 string                `json:"id"`
+	CreatedAt          utils.Timestamp       `json:"createdAt"`
+	UpdatedAt          utils.Timestamp       `json:"updatedAt,omitempty"`
+	ExpirationDateTime utils.Timestamp       `json:"expirationDateTime,omitempty"`
+	Status             ConsentStatus         `json:"status"`
+	PaymentID          string                `json:"paymentId,omitempty"`
+	CustomerID         string                `json:"customerId,omitempty"`
+	ExternalID         string                `json:"externalId,omitempty"`
+	ParticipantID      string                `json:"participantId,omitempty"`
+	User               *payments.User        `json:"user,omitempty"`
+	BusinessEntity     *payments.User        `json:"businessEntity,omitempty"`
+	Debtor             *payments.BankAccount `json:"debtor,omitempty"`
+	Creditor           *payments.BankAccount `json:"creditor,omitempty"`
+	Amount             float64               `json:"amount"`
+	RejectionReason    *RejectionReason      `json:"rejectionReason,omitempty"`
+	RevocationReason   string                `json:"revocationReason,omitempty"`
+}
+
+// IsExpired reports whether the consent expired before now without being
+// used. Consents without an expiration never expire.
+func (c *ConsentPayload) IsExpired(now time.Time) bool {
+	if c.ExpirationDateTime.IsZero() || c.Status == Consumed {
+		return false
+	}
+	return now.After(c.ExpirationDateTime.Time)
+}
+
+type Cursor struct {
+	AfterCursor  string `json:"afterCursor"`
+	BeforeCursor string `json:"beforeCursor"`
+}
+
+type ConsentsFilter struct {
+	CustomerID   string
+	ExternalID   string
+	Status       ConsentStatus
+	Limit        string
+	AfterCursor  string
+	BeforeCursor string
+}
+
+type ConsentsOutput struct {
+	Data   []ConsentPayload `json:"data"`
+	Cursor Cursor           `json:"cursor"`
+}
+
+type revokeRequest struct {
+	Status           ConsentStatus `json:"status"`
+	RevocationReason string        `json:"revocationReason,omitempty"`
+}
+
+func Get(accessToken, consentID string, authClient *auth.AuthClient) (*ConsentPayload, error) {
+	var output ConsentPayload
+	url := fmt.Sprintf("%s/consents/%s", authClient.Environment, consentID)
+	err := utils.DoRequest(http.MethodGet, url, accessToken, nil, &output)
+	if err != nil {
+		return nil, err
+	}
+
+	return &output, nil
+}
+
+// GetForPayment fetches the consent referenced by payment.
+func GetForPayment(accessToken string, payment *payments.PaymentStatusPayload, authClient *auth.AuthClient) (*ConsentPayload, error) {
+	if payment.ConsentID == "" {
+		return nil, fmt.Errorf("payment %s has no consent", payment.ID)
+	}
+
+	return Get(accessToken, payment.ConsentID, authClient)
+}
+
+// Revoke revokes a consent that was not used yet. Consents that are already
+// rejected, consumed or revoked are refused without calling the API.
+func Revoke(accessToken, consentID, reason string, authClient *auth.AuthClient) (*ConsentPayload, error) {
+	consent, err := Get(accessToken, consentID, authClient)
+	if err != nil {
+		return nil, err
+	}
+	if consent.Status != AwaitingAuthorization && consent.Status != Authorized {
+		return nil, fmt.Errorf("consent %s is %s and cannot be revoked", consentID, consent.Status)
+	}
+
+	var output ConsentPayload
+	url := fmt.Sprintf("%s/consents/%s", authClient.Environment, consentID)
+	err = utils.DoRequest(http.MethodPatch, url, accessToken, &revokeRequest{Status: Revoked, RevocationReason: reason}, &output)
+	if err != nil {
+		return nil, err
+	}
+
+	return &output, nil
+}
+
+func List(accessToken string, filters *ConsentsFilter, authClient *auth.AuthClient) (*ConsentsOutput, error) {
+	filterParams := make(url.Values)
+
+	if filters != nil {
+		if filters.CustomerID != "" {
+			filterParams.Set("customerId", filters.CustomerID)
+		}
+		if filters.ExternalID != "" {
+			filterParams.Set("externalId", filters.ExternalID)
+		}
+		if filters.Status != "" {
+			filterParams.Set("status", string(filters.Status))
+		}
+		if filters.Limit != "" {
+			filterParams.Set("limit", filters.Limit)
+		}
+		if filters.AfterCursor != "" {
+			filterParams.Set("afterCursor", filters.AfterCursor)
+		}
+		if filters.BeforeCursor != "" {
+			filterParams.Set("beforeCursor", filters.BeforeCursor)
+		}
+	}
+
+	var output ConsentsOutput
+	url := fmt.Sprintf("%s/consents?%s", authClient.Environment, filterParams.Encode())
+	err := utils.DoRequest(http.MethodGet, url, accessToken, nil, &output)
+	if err != nil {
+		return nil, err
+	}
+
+	return &output, nil
+}
@@ -0,0 +1,386 @@
+package enrollments
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+
+	"iniciador-sdk/iniciador/auth"
+	"iniciador-sdk/iniciador/payments"
+	"iniciador-sdk/iniciador/utils"
+)
+
+// EnrollmentStatus follows the enrollment lifecycle of the Open Finance
+// "Jornada sem Redirecionamento" (JSR): risk signals, account holder
+// validation in the bank, then FIDO registration.
+type EnrollmentStatus string
+
+const (
+	AwaitingRiskSignals             EnrollmentStatus = "AWAITING_RISK_SIGNALS"
+	AwaitingAccountHolderValidation EnrollmentStatus = "AWAITING_ACCOUNT_HOLDER_VALIDATION"
+	AwaitingEnrollment              EnrollmentStatus = "AWAITING_ENROLLMENT"
+	Authorized                      EnrollmentStatus = "AUTHORIZED"
+	Rejected                        EnrollmentStatus = "REJECTED"
+	Revoked                         EnrollmentStatus = "REVOKED"
+)
+
+type Platform string
+
+const (
+	Android Platform = "ANDROID"
+	IOS     Platform = "IOS"
+	Browser Platform = "BROWSER"
+)
+
+type RejectionReason struct {
+	Code   string `json:"code"`
+	Detail string `json:"detail"`
+}
+
+// EnrollmentPayload binds a device of the user to a debtor account, so later
+// payments are authorized with a FIDO assertion instead of a redirect to the
+// bank. Limits are in cents.
+type EnrollmentPayload struct {
+	utils.UnknownFields `json:"-"`
+
+	ID                    string                `json:"id"`
+	CreatedAt             utils.Timestamp       `json:"createdAt"`
+	UpdatedAt             utils.Timestamp       `json:"updatedAt,omitempty"`
+	ExpirationDateTime    utils.Timestamp       `json:"expirationDateTime,omitempty"`
+	Status                EnrollmentStatus      `json:"status,omitempty"`
+	ExternalID            string                `json:"externalId,omitempty"`
+	ParticipantID         string                `json:"participantId,omitempty"`
+	Name                  string                `json:"name,omitempty"`
+	User                  payments.User         `json:"user"`
+	BusinessEntity        *payments.User        `json:"businessEntity,omitempty"`
+	Debtor                *payments.BankAccount `json:"debtor,omitempty"`
+	DailyLimit            float64               `json:"dailyLimit,omitempty"`
+	TransactionLimit      float64               `json:"transactionLimit,omitempty"`
+	RedirectURL           string                `json:"redirectURL,omitempty"`
+	RedirectOnErrorURL    string                `json:"redirectOnErrorURL,omitempty"`
+	RedirectEnrollmentURL string                `json:"redirectEnrollmentURL,omitempty"`
+	RejectionReason       *RejectionReason      `json:"rejectionReason,omitempty"`
+	RevocationReason      string                `json:"revocationReason,omitempty"`
+}
+
+// RiskSignals describe the device at the time of the request. They are sent
+// once during enrollment and with every payment authorization.
+type RiskSignals struct {
+	DeviceID             string     `json:"deviceId"`
+	IsRootedDevice       bool       `json:"isRootedDevice"`
+	ScreenBrightness     float64    `json:"screenBrightness,omitempty"`
+	ElapsedTimeSinceBoot int64      `json:"elapsedTimeSinceBoot,omitempty"`
+	OSVersion            string     `json:"osVersion,omitempty"`
+	UserTimeZoneOffset   string     `json:"userTimeZoneOffset,omitempty"`
+	Language             string     `json:"language,omitempty"`
+	AccountTenure        utils.Date `json:"accountTenure,omitempty"`
+}
+
+// Binary WebAuthn values (challenges, credential IDs, client data,
+// authenticator data and signatures) are base64url strings, as in the
+// WebAuthn JSON serialization used by the mobile and browser APIs.
+
+type RelyingParty struct {
+	ID   string `json:"id"`
+	Name string `json:"name,omitempty"`
+}
+
+type UserEntity struct {
+	ID          string `json:"id"`
+	Name        string `json:"name"`
+	DisplayName string `json:"displayName,omitempty"`
+}
+
+type CredentialParameter struct {
+	Type string `json:"type"`
+	Alg  int    `json:"alg"`
+}
+
+type CredentialDescriptor struct {
+	Type       string   `json:"type"`
+	ID         string   `json:"id"`
+	Transports []string `json:"transports,omitempty"`
+}
+
+type AuthenticatorSelection struct {
+	AuthenticatorAttachment string `json:"authenticatorAttachment,omitempty"`
+	UserVerification        string `json:"userVerification,omitempty"`
+}
+
+type RegistrationOptionsInput struct {
+	RP       string   `json:"rp"`
+	Platform Platform `json:"platform"`
+}
+
+// RegistrationOptions are the PublicKeyCredentialCreationOptions to pass to
+// the device authenticator.
+type RegistrationOptions struct {
+	EnrollmentID           string                  `json:"enrollmentId"`
+	RP                     RelyingParty            `json:"rp"`
+	User                   UserEntity              `json:"user"`
+	Challenge              string                  `json:"challenge"`
+	PubKeyCredParams       []CredentialParameter   `json:"pubKeyCredParams"`
+	Timeout                int                     `json:"timeout,omitempty"`
+	ExcludeCredentials     []CredentialDescriptor  `json:"excludeCredentials,omitempty"`
+	AuthenticatorSelection *AuthenticatorSelection `json:"authenticatorSelection,omitempty"`
+	Attestation            string                  `json:"attestation,omitempty"`
+}
+
+type AttestationResponse struct {
+	ClientDataJSON    string `json:"clientDataJSON"`
+	AttestationObject string `json:"attestationObject"`
+	// PublicKey is the DER SubjectPublicKeyInfo of the credential, as
+	// returned by getPublicKey().
+	PublicKey          string `json:"publicKey,omitempty"`
+	PublicKeyAlgorithm int    `json:"publicKeyAlgorithm,omitempty"`
+}
+
+// FidoRegistration is the PublicKeyCredential created by the authenticator.
+type FidoRegistration struct {
+	ID       string              `json:"id"`
+	RawID    string              `json:"rawId"`
+	Type     string              `json:"type"`
+	Response AttestationResponse `json:"response"`
+}
+
+type SignOptionsInput struct {
+	RP        string   `json:"rp"`
+	Platform  Platform `json:"platform"`
+	PaymentID string   `json:"paymentId"`
+}
+
+// SignOptions are the PublicKeyCredentialRequestOptions to pass to the device
+// authenticator to authorize a payment.
+type SignOptions struct {
+	Challenge        string                 `json:"challenge"`
+	RPID             string                 `json:"rpId"`
+	AllowCredentials []CredentialDescriptor `json:"allowCredentials"`
+	Timeout          int                    `json:"timeout,omitempty"`
+	UserVerification string                 `json:"userVerification,omitempty"`
+}
+
+type AssertionResponse struct {
+	ClientDataJSON    string `json:"clientDataJSON"`
+	AuthenticatorData string `json:"authenticatorData"`
+	Signature         string `json:"signature"`
+	UserHandle        string `json:"userHandle,omitempty"`
+}
+
+// FidoAssertion is the PublicKeyCredential signed by the authenticator.
+type FidoAssertion struct {
+	ID       string            `json:"id"`
+	RawID    string            `json:"rawId"`
+	Type     string            `json:"type"`
+	Response AssertionResponse `json:"response"`
+}
+
+type AuthorizationInput struct {
+	FidoAssertion FidoAssertion `json:"fidoAssertion"`
+	RiskSignals   RiskSignals   `json:"riskSignals"`
+}
+
+type Cursor struct {
+	AfterCursor  string `json:"afterCursor"`
+	BeforeCursor string `json:"beforeCursor"`
+}
+
+type EnrollmentsFilter struct {
+	ExternalID   string
+	Status       EnrollmentStatus
+	Limit        string
+	AfterCursor  string
+	BeforeCursor string
+}
+
+type EnrollmentsOutput struct {
+	Data   []EnrollmentPayload `json:"data"`
+	Cursor Cursor              `json:"cursor"`
+}
+
+type revokeRequest struct {
+	Status           EnrollmentStatus `json:"status"`
+	RevocationReason string           `json:"revocationReason,omitempty"`
+}
+
+// ValidatePayment checks that payment can be authorized with the enrollment.
+func (e *EnrollmentPayload) ValidatePayment(payment *payments.PaymentInitiationPayload) error {
+	if e.Status != Authorized {
+		return fmt.Errorf("enrollment %s is %s, payments require an %s enrollment", e.ID, e.Status, Authorized)
+	}
+	if e.TransactionLimit != 0 && payment.Amount > e.TransactionLimit {
+		return fmt.Errorf("amount %.0f exceeds the enrollment transaction limit %.0f", payment.Amount, e.TransactionLimit)
+	}
+
+	return payment.Validate()
+}
+
+// Create starts an enrollment. Send the device risk signals next with
+// SendRiskSignals.
+func Create(accessToken string, enrollment *EnrollmentPayload, authClient *auth.AuthClient) (*EnrollmentPayload, error) {
+	if enrollment.User.TaxID == "" {
+		return nil, fmt.Errorf("user taxId is required")
+	}
+
+	var output EnrollmentPayload
+	url := fmt.Sprintf("%s/enrollments", authClient.Environment)
+	err := utils.DoRequest(http.MethodPost, url, accessToken, enrollment, &output)
+	if err != nil {
+		return nil, err
+	}
+
+	return &output, nil
+}
+
+func Get(accessToken, enrollmentID string, authClient *auth.AuthClient) (*EnrollmentPayload, error) {
+	var output EnrollmentPayload
+	url := fmt.Sprintf("%s/enrollments/%s", authClient.Environment, enrollmentID)
+	err := utils.DoRequest(http.MethodGet, url, accessToken, nil, &output)
+	if err != nil {
+		return nil, err
+	}
+
+	return &output, nil
+}
+
+func List(accessToken string, filters *EnrollmentsFilter, authClient *auth.AuthClient) (*EnrollmentsOutput, error) {
+	filterParams := make(url.Values)
+
+	if filters != nil {
+		if filters.ExternalID != "" {
+			filterParams.Set("externalId", filters.ExternalID)
+		}
+		if filters.Status != "" {
+			filterParams.Set("status", string(filters.Status))
+		}
+		if filters.Limit != "" {
+			filterParams.Set("limit", filters.Limit)
+		}
+		if filters.AfterCursor != "" {
+			filterParams.Set("afterCursor", filters.AfterCursor)
+		}
+		if filters.BeforeCursor != "" {
+			filterParams.Set("beforeCursor", filters.BeforeCursor)
+		}
+	}
+
+	var output EnrollmentsOutput
+	url := fmt.Sprintf("%s/enrollments?%s", authClient.Environment, filterParams.Encode())
+	err := utils.DoRequest(http.MethodGet, url, accessToken, nil, &output)
+	if err != nil {
+		return nil, err
+	}
+
+	return &output, nil
+}
+
+// SendRiskSignals sends the device risk signals of an enrollment awaiting
+// them. The returned enrollment carries the RedirectEnrollmentURL where the
+// user validates the account in their bank.
+func SendRiskSignals(accessToken, enrollmentID string, signals *RiskSignals, authClient *auth.AuthClient) (*EnrollmentPayload, error) {
+	// isRootedDevice is required, so false must be sent as well
+	payload, err := utils.MarshalWithFields(signals, "isRootedDevice")
+	if err != nil {
+		return nil, err
+	}
+
+	var output EnrollmentPayload
+	url := fmt.Sprintf("%s/enrollments/%s/risk-signals", authClient.Environment, enrollmentID)
+	err = utils.DoRequest(http.MethodPost, url, accessToken, json.RawMessage(payload), &output)
+	if err != nil {
+		return nil, err
+	}
+
+	return &output, nil
+}
+
+// GetRegistrationOptions fetches the options to create the FIDO credential of an
+// enrollment awaiting enrollment, once the user validated the account.
+func GetRegistrationOptions(accessToken, enrollmentID string, input *RegistrationOptionsInput, authClient *auth.AuthClient) (*RegistrationOptions, error) {
+	var output RegistrationOptions
+	url := fmt.Sprintf("%s/enrollments/%s/fido-registration-options", authClient.Environment, enrollmentID)
+	err := utils.DoRequest(http.MethodPost, url, accessToken, input, &output)
+	if err != nil {
+		return nil, err
+	}
+
+	return &output, nil
+}
+
+// Register sends the credential created with the registration options,
+// authorizing the enrollment.
+func Register(accessToken, enrollmentID string, registration *FidoRegistration, authClient *auth.AuthClient) (*EnrollmentPayload, error) {
+	var output EnrollmentPayload
+	url := fmt.Sprintf("%s/enrollments/%s/fido-registration", authClient.Environment, enrollmentID)
+	err := utils.DoRequest(http.MethodPost, url, accessToken, registration, &output)
+	if err != nil {
+		return nil, err
+	}
+
+	return &output, nil
+}
+
+func Revoke(accessToken, enrollmentID, reason string, authClient *auth.AuthClient) (*EnrollmentPayload, error) {
+	var output EnrollmentPayload
+	url := fmt.Sprintf("%s/enrollments/%s", authClient.Environment, enrollmentID)
+	err := utils.DoRequest(http.MethodPatch, url, accessToken, &revokeRequest{Status: Revoked, RevocationReason: reason}, &output)
+	if err != nil {
+		return nil, err
+	}
+
+	return &output, nil
+}
+
+// SendPayment creates a payment bound to an authorized enrollment. The
+// payment awaits authorization: fetch the sign options with GetSignOptions and
+// send the assertion with AuthorizePayment.
+func SendPayment(accessToken string, enrollment *EnrollmentPayload, payment *payments.PaymentInitiationPayload, authClient *auth.AuthClient) (*payments.PaymentInitiationPayload, error) {
+	err := enrollment.ValidatePayment(payment)
+	if err != nil {
+		return nil, err
+	}
+
+	var output payments.PaymentInitiationPayload
+	url := fmt.Sprintf("%s/enrollments/%s/payments", authClient.Environment, enrollment.ID)
+	err = utils.DoRequest(http.MethodPost, url, accessToken, payment, &output)
+	if err != nil {
+		return nil, err
+	}
+
+	return &output, nil
+}
+
+// GetSignOptions fetches the options to sign the authorization of a payment
+// created with SendPayment.
+func GetSignOptions(accessToken, enrollmentID string, input *SignOptionsInput, authClient *auth.AuthClient) (*SignOptions, error) {
+	if input.PaymentID == "" {
+		return nil, fmt.Errorf("paymentId is required")
+	}
+
+	var output SignOptions
+	url := fmt.Sprintf("%s/enrollments/%s/fido-sign-options", authClient.Environment, enrollmentID)
+	err := utils.DoRequest(http.MethodPost, url, accessToken, input, &output)
+	if err != nil {
+		return nil, err
+	}
+
+	return &output, nil
+}
+
+// AuthorizePayment authorizes a payment created with SendPayment with the
+// assertion signed using the sign options.
+func AuthorizePayment(accessToken, enrollmentID, paymentID string, input *AuthorizationInput, authClient *auth.AuthClient) (*payments.PaymentInitiationPayload, error) {
+	payload, err := utils.MarshalWithFields(input, "riskSignals.isRootedDevice")
+	if err != nil {
+		return nil, err
+	}
+
+	var output payments.PaymentInitiationPayload
+	url := fmt.Sprintf("%s/enrollments/%s/payments/%s/authorize", authClient.Environment, enrollmentID, paymentID)
+	err = utils.DoRequest(http.MethodPost, url, accessToken, json.RawMessage(payload), &output)
+	if err != nil {
+		return nil, err
+	}
+
+	return &output, nil
+}
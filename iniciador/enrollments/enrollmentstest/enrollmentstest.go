@@ -0,0 +1,520 @@
+// Package enrollmentstest provides a local stub of the enrollments API and a
+// software FIDO authenticator, to exercise the whole JSR flow in tests without
+// a bank or a device.
+package enrollmentstest
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/asn1"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"time"
+
+	"iniciador-sdk/iniciador/enrollments"
+	"iniciador-sdk/iniciador/payments"
+	"iniciador-sdk/iniciador/utils"
+)
+
+// algES256 is the COSE identifier of ECDSA with P-256 and SHA-256.
+const algES256 = -7
+
+// Server is an in-memory enrollments API. Point the AuthClient Environment at
+// URL. Payments are completed as soon as they are authorized.
+type Server struct {
+	*httptest.Server
+
+	// Origin is the origin expected in the client data of the credentials.
+	Origin string
+
+	mutex       sync.Mutex
+	nextID      int
+	enrollments map[string]*enrollment
+	payments    map[string]*payment
+}
+
+type enrollment struct {
+	payload      enrollments.EnrollmentPayload
+	challenge    string
+	credentialID string
+	publicKey    *ecdsa.PublicKey
+	signCount    uint32
+}
+
+type payment struct {
+	payload      payments.PaymentInitiationPayload
+	enrollmentID string
+	challenge    string
+}
+
+type apiError struct {
+	status  int
+	message string
+}
+
+func (e *apiError) Error() string {
+	return e.message
+}
+
+func errorf(status int, format string, args ...interface{}) *apiError {
+	return &apiError{status: status, message: fmt.Sprintf(format, args...)}
+}
+
+// NewServer starts a stub server expecting credentials created for origin.
+func NewServer(origin string) *Server {
+	s := &Server{
+		Origin:      origin,
+		enrollments: make(map[string]*enrollment),
+		payments:    make(map[string]*payment),
+	}
+	s.Server = httptest.NewServer(http.HandlerFunc(s.handle))
+	return s
+}
+
+// ValidateAccountHolder simulates the user validating the account in the bank
+// after following RedirectEnrollmentURL.
+func (s *Server) ValidateAccountHolder(enrollmentID string) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	e, ok := s.enrollments[enrollmentID]
+	if !ok {
+		return fmt.Errorf("enrollment %s not found", enrollmentID)
+	}
+	if e.payload.Status != enrollments.AwaitingAccountHolderValidation {
+		return fmt.Errorf("enrollment %s is %s", enrollmentID, e.payload.Status)
+	}
+	s.setStatus(e, enrollments.AwaitingEnrollment)
+	return nil
+}
+
+func (s *Server) handle(w http.ResponseWriter, r *http.Request) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	output, err := s.route(r)
+	if err != nil {
+		status := http.StatusInternalServerError
+		if apiErr, ok := err.(*apiError); ok {
+			status = apiErr.status
+		}
+		writeJSON(w, status, utils.Error{
+			ErrorCode:  http.StatusText(status),
+			Message:    []string{err.Error()},
+			Method:     r.Method,
+			Path:       r.URL.Path,
+			StatusCode: status,
+			Timestamp:  time.Now().UTC().Format(time.RFC3339),
+		})
+		return
+	}
+	writeJSON(w, http.StatusOK, output)
+}
+
+func (s *Server) route(r *http.Request) (interface{}, error) {
+	parts := strings.Split(strings.Trim(r.URL.Path, "/"), "/")
+	if parts[0] != "enrollments" {
+		return nil, errorf(http.StatusNotFound, "unknown path %s", r.URL.Path)
+	}
+
+	switch {
+	case len(parts) == 1 && r.Method == http.MethodPost:
+		var input enrollments.EnrollmentPayload
+		if err := json.NewDecoder(r.Body).Decode(&input); err != nil {
+			return nil, errorf(http.StatusBadRequest, "invalid body: %v", err)
+		}
+		return s.create(&input), nil
+	case len(parts) == 1 && r.Method == http.MethodGet:
+		output := enrollments.EnrollmentsOutput{Data: []enrollments.EnrollmentPayload{}}
+		for _, e := range s.enrollments {
+			if status := r.URL.Query().Get("status"); status == "" || status == string(e.payload.Status) {
+				output.Data = append(output.Data, e.payload)
+			}
+		}
+		return output, nil
+	}
+
+	e, ok := s.enrollments[parts[1]]
+	if !ok {
+		return nil, errorf(http.StatusNotFound, "enrollment %s not found", parts[1])
+	}
+
+	switch {
+	case len(parts) == 2 && r.Method == http.MethodGet:
+		return e.payload, nil
+	case len(parts) == 2 && r.Method == http.MethodPatch:
+		return s.revoke(e)
+	case len(parts) == 3 && parts[2] == "risk-signals" && r.Method == http.MethodPost:
+		var input enrollments.RiskSignals
+		if err := json.NewDecoder(r.Body).Decode(&input); err != nil {
+			return nil, errorf(http.StatusBadRequest, "invalid body: %v", err)
+		}
+		return s.riskSignals(e, &input)
+	case len(parts) == 3 && parts[2] == "fido-registration-options" && r.Method == http.MethodPost:
+		var input enrollments.RegistrationOptionsInput
+		if err := json.NewDecoder(r.Body).Decode(&input); err != nil {
+			return nil, errorf(http.StatusBadRequest, "invalid body: %v", err)
+		}
+		return s.registrationOptions(e, &input)
+	case len(parts) == 3 && parts[2] == "fido-registration" && r.Method == http.MethodPost:
+		var input enrollments.FidoRegistration
+		if err := json.NewDecoder(r.Body).Decode(&input); err != nil {
+			return nil, errorf(http.StatusBadRequest, "invalid body: %v", err)
+		}
+		return s.register(e, &input)
+	case len(parts) == 3 && parts[2] == "fido-sign-options" && r.Method == http.MethodPost:
+		var input enrollments.SignOptionsInput
+		if err := json.NewDecoder(r.Body).Decode(&input); err != nil {
+			return nil, errorf(http.StatusBadRequest, "invalid body: %v", err)
+		}
+		return s.signOptions(e, &input)
+	case len(parts) == 3 && parts[2] == "payments" && r.Method == http.MethodPost:
+		var input payments.PaymentInitiationPayload
+		if err := json.NewDecoder(r.Body).Decode(&input); err != nil {
+			return nil, errorf(http.StatusBadRequest, "invalid body: %v", err)
+		}
+		return s.createPayment(e, &input)
+	case len(parts) == 5 && parts[2] == "payments" && parts[4] == "authorize" && r.Method == http.MethodPost:
+		var input enrollments.AuthorizationInput
+		if err := json.NewDecoder(r.Body).Decode(&input); err != nil {
+			return nil, errorf(http.StatusBadRequest, "invalid body: %v", err)
+		}
+		return s.authorize(e, parts[3], &input)
+	default:
+		return nil, errorf(http.StatusNotFound, "unknown path %s %s", r.Method, r.URL.Path)
+	}
+}
+
+func (s *Server) newID(prefix string) string {
+	s.nextID++
+	return fmt.Sprintf("%s-%d", prefix, s.nextID)
+}
+
+func (s *Server) setStatus(e *enrollment, status enrollments.EnrollmentStatus) {
+	e.payload.Status = status
+	e.payload.UpdatedAt = utils.NewTimestamp(time.Now())
+}
+
+func (s *Server) create(input *enrollments.EnrollmentPayload) enrollments.EnrollmentPayload {
+	e := &enrollment{payload: *input}
+	e.payload.ID = s.newID("enrollment")
+	e.payload.CreatedAt = utils.NewTimestamp(time.Now())
+	e.payload.Status = enrollments.AwaitingRiskSignals
+	s.enrollments[e.payload.ID] = e
+	return e.payload
+}
+
+func (s *Server) riskSignals(e *enrollment, input *enrollments.RiskSignals) (interface{}, error) {
+	if e.payload.Status != enrollments.AwaitingRiskSignals {
+		return nil, errorf(http.StatusUnprocessableEntity, "enrollment %s is %s", e.payload.ID, e.payload.Status)
+	}
+	if input.DeviceID == "" {
+		return nil, errorf(http.StatusBadRequest, "deviceId is required")
+	}
+	if input.IsRootedDevice {
+		s.setStatus(e, enrollments.Rejected)
+		e.payload.RejectionReason = &enrollments.RejectionReason{Code: "RISCO", Detail: "rooted device"}
+		return e.payload, nil
+	}
+
+	s.setStatus(e, enrollments.AwaitingAccountHolderValidation)
+	e.payload.RedirectEnrollmentURL = fmt.Sprintf("%s/bank/enrollments/%s", s.URL, e.payload.ID)
+	return e.payload, nil
+}
+
+func (s *Server) registrationOptions(e *enrollment, input *enrollments.RegistrationOptionsInput) (interface{}, error) {
+	if e.payload.Status != enrollments.AwaitingEnrollment {
+		return nil, errorf(http.StatusUnprocessableEntity, "enrollment %s is %s", e.payload.ID, e.payload.Status)
+	}
+
+	e.challenge = newChallenge()
+	return enrollments.RegistrationOptions{
+		EnrollmentID:     e.payload.ID,
+		RP:               enrollments.RelyingParty{ID: input.RP, Name: input.RP},
+		User:             enrollments.UserEntity{ID: encode([]byte(e.payload.User.TaxID)), Name: e.payload.User.TaxID, DisplayName: e.payload.User.Name},
+		Challenge:        e.challenge,
+		PubKeyCredParams: []enrollments.CredentialParameter{{Type: "public-key", Alg: algES256}},
+		Timeout:          300000,
+		Attestation:      "none",
+	}, nil
+}
+
+func (s *Server) register(e *enrollment, input *enrollments.FidoRegistration) (interface{}, error) {
+	if e.payload.Status != enrollments.AwaitingEnrollment || e.challenge == "" {
+		return nil, errorf(http.StatusUnprocessableEntity, "enrollment %s is not awaiting a registration", e.payload.ID)
+	}
+	err := s.verifyClientData(input.Response.ClientDataJSON, "webauthn.create", e.challenge)
+	if err != nil {
+		return nil, err
+	}
+
+	publicKeyBytes, err := decode(input.Response.PublicKey)
+	if err != nil {
+		return nil, errorf(http.StatusBadRequest, "invalid publicKey: %v", err)
+	}
+	publicKey, err := x509.ParsePKIXPublicKey(publicKeyBytes)
+	if err != nil {
+		return nil, errorf(http.StatusBadRequest, "invalid publicKey: %v", err)
+	}
+	ecdsaKey, ok := publicKey.(*ecdsa.PublicKey)
+	if !ok {
+		return nil, errorf(http.StatusBadRequest, "publicKey must be an ES256 key")
+	}
+
+	e.challenge = ""
+	e.credentialID = input.ID
+	e.publicKey = ecdsaKey
+	s.setStatus(e, enrollments.Authorized)
+	return e.payload, nil
+}
+
+func (s *Server) revoke(e *enrollment) (interface{}, error) {
+	if e.payload.Status == enrollments.Rejected || e.payload.Status == enrollments.Revoked {
+		return nil, errorf(http.StatusUnprocessableEntity, "enrollment %s is %s", e.payload.ID, e.payload.Status)
+	}
+	s.setStatus(e, enrollments.Revoked)
+	return e.payload, nil
+}
+
+func (s *Server) createPayment(e *enrollment, input *payments.PaymentInitiationPayload) (interface{}, error) {
+	if e.payload.Status != enrollments.Authorized {
+		return nil, errorf(http.StatusUnprocessableEntity, "enrollment %s is %s", e.payload.ID, e.payload.Status)
+	}
+
+	status := payments.ConsentAwaitingAuthorization
+	p := &payment{payload: *input, enrollmentID: e.payload.ID}
+	p.payload.ID = s.newID("payment")
+	p.payload.ConsentID = s.newID("consent")
+	p.payload.CreatedAt = utils.NewTimestamp(time.Now())
+	p.payload.Status = &status
+	s.payments[p.payload.ID] = p
+	return p.payload, nil
+}
+
+func (s *Server) signOptions(e *enrollment, input *enrollments.SignOptionsInput) (interface{}, error) {
+	p, ok := s.payments[input.PaymentID]
+	if !ok || p.enrollmentID != e.payload.ID {
+		return nil, errorf(http.StatusNotFound, "payment %s not found in enrollment %s", input.PaymentID, e.payload.ID)
+	}
+	if e.payload.Status != enrollments.Authorized {
+		return nil, errorf(http.StatusUnprocessableEntity, "enrollment %s is %s", e.payload.ID, e.payload.Status)
+	}
+
+	p.challenge = newChallenge()
+	return enrollments.SignOptions{
+		Challenge:        p.challenge,
+		RPID:             input.RP,
+		AllowCredentials: []enrollments.CredentialDescriptor{{Type: "public-key", ID: e.credentialID}},
+		Timeout:          300000,
+		UserVerification: "required",
+	}, nil
+}
+
+func (s *Server) authorize(e *enrollment, paymentID string, input *enrollments.AuthorizationInput) (interface{}, error) {
+	p, ok := s.payments[paymentID]
+	if !ok || p.enrollmentID != e.payload.ID {
+		return nil, errorf(http.StatusNotFound, "payment %s not found in enrollment %s", paymentID, e.payload.ID)
+	}
+	if e.payload.Status != enrollments.Authorized {
+		return nil, errorf(http.StatusUnprocessableEntity, "enrollment %s is %s", e.payload.ID, e.payload.Status)
+	}
+	if p.challenge == "" {
+		return nil, errorf(http.StatusUnprocessableEntity, "payment %s has no pending sign options", paymentID)
+	}
+
+	assertion := &input.FidoAssertion
+	if assertion.ID != e.credentialID {
+		return nil, errorf(http.StatusUnprocessableEntity, "unknown credential %s", assertion.ID)
+	}
+	err := s.verifyClientData(assertion.Response.ClientDataJSON, "webauthn.get", p.challenge)
+	if err != nil {
+		return nil, err
+	}
+	signCount, err := verifySignature(e.publicKey, &assertion.Response)
+	if err != nil {
+		return nil, err
+	}
+	if signCount <= e.signCount {
+		return nil, errorf(http.StatusUnprocessableEntity, "signature counter did not increase, the credential may be cloned")
+	}
+	e.signCount = signCount
+
+	p.challenge = ""
+	status := payments.PaymentCompleted
+	p.payload.Status = &status
+	return p.payload, nil
+}
+
+type clientData struct {
+	Type      string `json:"type"`
+	Challenge string `json:"challenge"`
+	Origin    string `json:"origin"`
+}
+
+func (s *Server) verifyClientData(encoded, expectedType, challenge string) error {
+	data, err := decode(encoded)
+	if err != nil {
+		return errorf(http.StatusBadRequest, "invalid clientDataJSON: %v", err)
+	}
+	var client clientData
+	err = json.Unmarshal(data, &client)
+	if err != nil {
+		return errorf(http.StatusBadRequest, "invalid clientDataJSON: %v", err)
+	}
+	if client.Type != expectedType || client.Challenge != challenge || client.Origin != s.Origin {
+		return errorf(http.StatusUnprocessableEntity, "clientDataJSON does not match the issued options")
+	}
+	return nil
+}
+
+type ecdsaSignature struct {
+	R, S *big.Int
+}
+
+// verifySignature checks the assertion signature and returns the signature
+// counter of the authenticator data.
+func verifySignature(publicKey *ecdsa.PublicKey, response *enrollments.AssertionResponse) (uint32, error) {
+	authenticatorData, err := decode(response.AuthenticatorData)
+	if err != nil {
+		return 0, errorf(http.StatusBadRequest, "invalid authenticatorData: %v", err)
+	}
+	if len(authenticatorData) < 37 {
+		return 0, errorf(http.StatusBadRequest, "authenticatorData is too short")
+	}
+	clientDataJSON, err := decode(response.ClientDataJSON)
+	if err != nil {
+		return 0, errorf(http.StatusBadRequest, "invalid clientDataJSON: %v", err)
+	}
+	signatureBytes, err := decode(response.Signature)
+	if err != nil {
+		return 0, errorf(http.StatusBadRequest, "invalid signature: %v", err)
+	}
+
+	var signature ecdsaSignature
+	_, err = asn1.Unmarshal(signatureBytes, &signature)
+	if err != nil {
+		return 0, errorf(http.StatusBadRequest, "invalid signature: %v", err)
+	}
+	if !ecdsa.Verify(publicKey, signedDigest(authenticatorData, clientDataJSON), signature.R, signature.S) {
+		return 0, errorf(http.StatusUnprocessableEntity, "invalid signature")
+	}
+	return binary.BigEndian.Uint32(authenticatorData[33:]), nil
+}
+
+// Authenticator is a software FIDO authenticator holding one ES256
+// credential. Its attestation object is not a CBOR attestation: the stub
+// server registers the credential from the publicKey of the response.
+type Authenticator struct {
+	Origin string
+
+	credentialID string
+	privateKey   *ecdsa.PrivateKey
+	signCount    uint32
+}
+
+func NewAuthenticator(origin string) (*Authenticator, error) {
+	privateKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		return nil, err
+	}
+	return &Authenticator{Origin: origin, credentialID: newChallenge(), privateKey: privateKey}, nil
+}
+
+// Register creates the credential for the registration options.
+func (a *Authenticator) Register(options *enrollments.RegistrationOptions) (*enrollments.FidoRegistration, error) {
+	publicKey, err := x509.MarshalPKIXPublicKey(&a.privateKey.PublicKey)
+	if err != nil {
+		return nil, err
+	}
+	clientDataJSON, err := json.Marshal(clientData{Type: "webauthn.create", Challenge: options.Challenge, Origin: a.Origin})
+	if err != nil {
+		return nil, err
+	}
+
+	return &enrollments.FidoRegistration{
+		ID:    a.credentialID,
+		RawID: a.credentialID,
+		Type:  "public-key",
+		Response: enrollments.AttestationResponse{
+			ClientDataJSON:     encode(clientDataJSON),
+			AttestationObject:  encode(a.authenticatorData(options.RP.ID)),
+			PublicKey:          encode(publicKey),
+			PublicKeyAlgorithm: algES256,
+		},
+	}, nil
+}
+
+// Sign signs an assertion for the sign options.
+func (a *Authenticator) Sign(options *enrollments.SignOptions) (*enrollments.FidoAssertion, error) {
+	clientDataJSON, err := json.Marshal(clientData{Type: "webauthn.get", Challenge: options.Challenge, Origin: a.Origin})
+	if err != nil {
+		return nil, err
+	}
+	a.signCount++
+	authenticatorData := a.authenticatorData(options.RPID)
+
+	r, s, err := ecdsa.Sign(rand.Reader, a.privateKey, signedDigest(authenticatorData, clientDataJSON))
+	if err != nil {
+		return nil, err
+	}
+	signature, err := asn1.Marshal(ecdsaSignature{R: r, S: s})
+	if err != nil {
+		return nil, err
+	}
+
+	return &enrollments.FidoAssertion{
+		ID:    a.credentialID,
+		RawID: a.credentialID,
+		Type:  "public-key",
+		Response: enrollments.AssertionResponse{
+			ClientDataJSON:    encode(clientDataJSON),
+			AuthenticatorData: encode(authenticatorData),
+			Signature:         encode(signature),
+		},
+	}, nil
+}
+
+// authenticatorData is the RP ID hash, the user present and verified flags
+// and the signature counter.
+func (a *Authenticator) authenticatorData(rpID string) []byte {
+	rpIDHash := sha256.Sum256([]byte(rpID))
+	data := append(rpIDHash[:], 0x05, 0, 0, 0, 0)
+	binary.BigEndian.PutUint32(data[33:], a.signCount)
+	return data
+}
+
+func signedDigest(authenticatorData, clientDataJSON []byte) []byte {
+	clientDataHash := sha256.Sum256(clientDataJSON)
+	digest := sha256.Sum256(append(append([]byte{}, authenticatorData...), clientDataHash[:]...))
+	return digest[:]
+}
+
+func newChallenge() string {
+	challenge := make([]byte, 32)
+	_, _ = rand.Read(challenge)
+	return encode(challenge)
+}
+
+func encode(data []byte) string {
+	return base64.RawURLEncoding.EncodeToString(data)
+}
+
+func decode(data string) ([]byte, error) {
+	return base64.RawURLEncoding.DecodeString(data)
+}
+
+func writeJSON(w http.ResponseWriter, status int, body interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(body)
+}
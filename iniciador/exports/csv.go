@@ -0,0 +1,128 @@
+package exports
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"strings"
+
+	"iniciador-sdk/iniciador/utils"
+)
+
+// Column is a CSV column: its header and how a record is formatted in it.
+// Values of columns that are not Numeric are written as text: those starting
+// with "=", "+", "-", "@", a tab or a carriage return are prefixed with "'",
+// so spreadsheets do not evaluate them as formulas.
+type Column struct {
+	Header  string
+	Value   func(record *Record) string
+	Numeric bool
+}
+
+var (
+	ColumnID                        = Column{Header: "id", Value: func(r *Record) string { return r.ID }}
+	ColumnExternalID                = Column{Header: "externalId", Value: func(r *Record) string { return r.ExternalID }}
+	ColumnEndToEndID                = Column{Header: "endToEndId", Value: func(r *Record) string { return r.EndToEndID }}
+	ColumnTransactionIdentification = Column{Header: "transactionIdentification", Value: func(r *Record) string { return r.TransactionIdentification }}
+	ColumnConsentID                 = Column{Header: "consentId", Value: func(r *Record) string { return r.ConsentID }}
+	ColumnStatus                    = Column{Header: "status", Value: func(r *Record) string { return r.Status }}
+	ColumnMethod                    = Column{Header: "method", Value: func(r *Record) string { return r.Method }}
+	ColumnAmount                    = Column{Header: "amount", Value: func(r *Record) string { return utils.FormatDecimal(r.Amount) }, Numeric: true}
+	ColumnDate                      = Column{Header: "date", Value: func(r *Record) string { return r.Date.String() }}
+	ColumnCreatedAt                 = Column{Header: "createdAt", Value: func(r *Record) string { return formatTime(r.CreatedAt) }}
+	ColumnUpdatedAt                 = Column{Header: "updatedAt", Value: func(r *Record) string { return formatTime(r.UpdatedAt) }}
+	ColumnDescription               = Column{Header: "description", Value: func(r *Record) string { return r.Description }}
+	ColumnPayerName                 = Column{Header: "payerName", Value: func(r *Record) string { return r.PayerName }}
+	ColumnPayerTaxID                = Column{Header: "payerTaxId", Value: func(r *Record) string { return r.PayerTaxID }}
+	ColumnPayeeName                 = Column{Header: "payeeName", Value: func(r *Record) string { return r.PayeeName }}
+	ColumnPayeeTaxID                = Column{Header: "payeeTaxId", Value: func(r *Record) string { return r.PayeeTaxID }}
+	ColumnPixKey                    = Column{Header: "pixKey", Value: func(r *Record) string { return r.PixKey }}
+	ColumnErrorCode                 = Column{Header: "errorCode", Value: func(r *Record) string { return r.ErrorCode }}
+)
+
+// MetadataColumn is a column with the metadata value of key.
+func MetadataColumn(key string) Column {
+	return Column{Header: key, Value: func(r *Record) string {
+		value, ok := r.Metadata[key]
+		if !ok || value == nil {
+			return ""
+		}
+		return fmt.Sprint(value)
+	}}
+}
+
+// DefaultColumns are the columns of a CSVWriter created without columns.
+func DefaultColumns() []Column {
+	return []Column{
+		ColumnID, ColumnExternalID, ColumnEndToEndID, ColumnStatus, ColumnMethod,
+		ColumnAmount, ColumnDate, ColumnCreatedAt, ColumnUpdatedAt, ColumnDescription,
+	}
+}
+
+// CSVWriter writes a header row followed by a row per record. Amounts are
+// decimal reais with a point separator, e.g. "1234.56".
+type CSVWriter struct {
+	// Comma is the field delimiter, "," by default. Set it before the first
+	// Write, e.g. to ';' for spreadsheets in Brazilian locale.
+	Comma rune
+
+	writer      *csv.Writer
+	columns     []Column
+	wroteHeader bool
+}
+
+// NewCSVWriter creates a CSVWriter with the given columns, or DefaultColumns
+// when none are given.
+func NewCSVWriter(w io.Writer, columns ...Column) *CSVWriter {
+	if len(columns) == 0 {
+		columns = DefaultColumns()
+	}
+	return &CSVWriter{Comma: ',', writer: csv.NewWriter(w), columns: columns}
+}
+
+func (c *CSVWriter) Write(record *Record) error {
+	err := c.writeHeader()
+	if err != nil {
+		return err
+	}
+
+	row := make([]string, len(c.columns))
+	for i, column := range c.columns {
+		row[i] = column.Value(record)
+		if !column.Numeric {
+			row[i] = escapeFormula(row[i])
+		}
+	}
+	return c.writer.Write(row)
+}
+
+func (c *CSVWriter) Close() error {
+	err := c.writeHeader()
+	if err != nil {
+		return err
+	}
+	c.writer.Flush()
+	return c.writer.Error()
+}
+
+func (c *CSVWriter) writeHeader() error {
+	if c.wroteHeader {
+		return nil
+	}
+	c.wroteHeader = true
+	c.writer.Comma = c.Comma
+
+	header := make([]string, len(c.columns))
+	for i, column := range c.columns {
+		header[i] = column.Header
+	}
+	return c.writer.Write(header)
+}
+
+// escapeFormula prefixes text that spreadsheets would evaluate as a formula.
+func escapeFormula(value string) string {
+	if value != "" && strings.ContainsRune("=+-@\t\r", rune(value[0])) {
+		return "'" + value
+	}
+	return value
+}
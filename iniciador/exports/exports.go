@@ -0,0 +1,117 @@
+package exports
+
+import (
+	"time"
+
+	"iniciador-sdk/iniciador/payments"
+	"iniciador-sdk/iniciador/utils"
+)
+
+// Record is a payment flattened for export. Times are in Brazil time and
+// Amount is in cents.
+type Record struct {
+	ID                        string
+	ExternalID                string
+	EndToEndID                string
+	TransactionIdentification string
+	ConsentID                 string
+	Status                    string
+	Method                    string
+	Amount                    float64
+	Date                      utils.Date
+	CreatedAt                 time.Time
+	UpdatedAt                 time.Time
+	Description               string
+	PayerName                 string
+	PayerTaxID                string
+	PayeeName                 string
+	PayeeTaxID                string
+	PixKey                    string
+	ErrorCode                 string
+	Metadata                  payments.Metadata
+}
+
+// FromPayment flattens a payment returned by Send or Get.
+func FromPayment(payment *payments.PaymentInitiationPayload) *Record {
+	record := &Record{
+		ID:                        payment.ID,
+		ExternalID:                payment.ExternalID,
+		EndToEndID:                payment.EndToEndID,
+		TransactionIdentification: payment.TransactionIdentification,
+		ConsentID:                 payment.ConsentID,
+		Method:                    string(payment.Method),
+		Amount:                    payment.Amount,
+		Date:                      payment.Date,
+		CreatedAt:                 inBrazil(payment.CreatedAt),
+		UpdatedAt:                 inBrazil(payment.UpdatedAt),
+		Description:               payment.Description,
+		PayerName:                 payment.User.Name,
+		PayerTaxID:                payment.User.TaxID,
+		PixKey:                    payment.PixKey,
+		Metadata:                  payment.Metadata,
+	}
+	if payment.Status != nil {
+		record.Status = string(*payment.Status)
+	}
+	if payment.Creditor != nil {
+		record.PayeeName = payment.Creditor.Name
+		record.PayeeTaxID = payment.Creditor.TaxID
+	}
+	if payment.Error != nil {
+		record.ErrorCode = payment.Error.Code
+	}
+	return record
+}
+
+// FromStatus flattens a payment status returned by Status.
+func FromStatus(status *payments.PaymentStatusPayload) *Record {
+	record := &Record{
+		ID:                        status.ID,
+		ExternalID:                status.ExternalID,
+		EndToEndID:                status.EndToEndID,
+		TransactionIdentification: status.TransactionIdentification,
+		ConsentID:                 status.ConsentID,
+		Status:                    status.Status,
+		Amount:                    status.Amount,
+		Date:                      status.Date,
+		CreatedAt:                 inBrazil(status.CreatedAt),
+		UpdatedAt:                 inBrazil(status.UpdatedAt),
+	}
+	if status.Error != nil {
+		record.ErrorCode = status.Error.Code
+	}
+	return record
+}
+
+func inBrazil(timestamp utils.Timestamp) time.Time {
+	if timestamp.IsZero() {
+		return time.Time{}
+	}
+	return timestamp.InBrazil()
+}
+
+// Writer streams records to a file format. Close writes any trailer and
+// flushes; it does not close the underlying io.Writer.
+type Writer interface {
+	Write(record *Record) error
+	Close() error
+}
+
+// WriteAll writes every record and closes the writer.
+func WriteAll(writer Writer, records []*Record) error {
+	for _, record := range records {
+		err := writer.Write(record)
+		if err != nil {
+			return err
+		}
+	}
+	return writer.Close()
+}
+
+// formatTime formats t as RFC3339 in Brazil time, or empty when zero.
+func formatTime(t time.Time) string {
+	if t.IsZero() {
+		return ""
+	}
+	return t.In(utils.BrazilLocation).Format(time.RFC3339)
+}
@@ -0,0 +1,73 @@
+package exports
+
+import (
+	"encoding/json"
+	"io"
+
+	"iniciador-sdk/iniciador/utils"
+)
+
+// jsonRecord is the JSON Lines form of a record. Amount is a decimal number
+// in reais written from the integer cents, so it is exact.
+type jsonRecord struct {
+	ID                        string                 `json:"id"`
+	ExternalID                string                 `json:"externalId,omitempty"`
+	EndToEndID                string                 `json:"endToEndId,omitempty"`
+	TransactionIdentification string                 `json:"transactionIdentification,omitempty"`
+	ConsentID                 string                 `json:"consentId,omitempty"`
+	Status                    string                 `json:"status,omitempty"`
+	Method                    string                 `json:"method,omitempty"`
+	Amount                    json.Number            `json:"amount"`
+	AmountCents               int64                  `json:"amountCents"`
+	Date                      string                 `json:"date,omitempty"`
+	CreatedAt                 string                 `json:"createdAt,omitempty"`
+	UpdatedAt                 string                 `json:"updatedAt,omitempty"`
+	Description               string                 `json:"description,omitempty"`
+	PayerName                 string                 `json:"payerName,omitempty"`
+	PayerTaxID                string                 `json:"payerTaxId,omitempty"`
+	PayeeName                 string                 `json:"payeeName,omitempty"`
+	PayeeTaxID                string                 `json:"payeeTaxId,omitempty"`
+	PixKey                    string                 `json:"pixKey,omitempty"`
+	ErrorCode                 string                 `json:"errorCode,omitempty"`
+	Metadata                  map[string]interface{} `json:"metadata,omitempty"`
+}
+
+// JSONLinesWriter writes a JSON object per line for each record.
+type JSONLinesWriter struct {
+	encoder *json.Encoder
+}
+
+func NewJSONLinesWriter(w io.Writer) *JSONLinesWriter {
+	encoder := json.NewEncoder(w)
+	encoder.SetEscapeHTML(false)
+	return &JSONLinesWriter{encoder: encoder}
+}
+
+func (j *JSONLinesWriter) Write(record *Record) error {
+	return j.encoder.Encode(&jsonRecord{
+		ID:                        record.ID,
+		ExternalID:                record.ExternalID,
+		EndToEndID:                record.EndToEndID,
+		TransactionIdentification: record.TransactionIdentification,
+		ConsentID:                 record.ConsentID,
+		Status:                    record.Status,
+		Method:                    record.Method,
+		Amount:                    json.Number(utils.FormatDecimal(record.Amount)),
+		AmountCents:               utils.AmountCents(record.Amount),
+		Date:                      record.Date.String(),
+		CreatedAt:                 formatTime(record.CreatedAt),
+		UpdatedAt:                 formatTime(record.UpdatedAt),
+		Description:               record.Description,
+		PayerName:                 record.PayerName,
+		PayerTaxID:                record.PayerTaxID,
+		PayeeName:                 record.PayeeName,
+		PayeeTaxID:                record.PayeeTaxID,
+		PixKey:                    record.PixKey,
+		ErrorCode:                 record.ErrorCode,
+		Metadata:                  record.Metadata,
+	})
+}
+
+func (j *JSONLinesWriter) Close() error {
+	return nil
+}
@@ -0,0 +1,133 @@
+package exports
+
+import (
+	"bufio"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"iniciador-sdk/iniciador/payments"
+	"iniciador-sdk/iniciador/utils"
+)
+
+// OFXStatement identifies the account and period of an OFX statement.
+type OFXStatement struct {
+	BankID      string
+	BranchID    string
+	AccountID   string
+	AccountType string // CHECKING by default
+	Start       time.Time
+	End         time.Time
+	// Debit lists the payments as debits, for the payer account. By default
+	// they are credits, for the account receiving the payments.
+	Debit bool
+}
+
+// OFXWriter writes an OFX 2.2 bank statement. A statement lists settled
+// transactions, so only PAYMENT_COMPLETED records are written; the others
+// are skipped. The ledger balance is the net amount of the transactions.
+type OFXWriter struct {
+	writer      *bufio.Writer
+	statement   OFXStatement
+	wroteHeader bool
+	balance     int64
+}
+
+func NewOFXWriter(w io.Writer, statement OFXStatement) *OFXWriter {
+	if statement.AccountType == "" {
+		statement.AccountType = "CHECKING"
+	}
+	return &OFXWriter{writer: bufio.NewWriter(w), statement: statement}
+}
+
+func (o *OFXWriter) Write(record *Record) error {
+	o.writeHeader()
+	if record.Status != string(payments.PaymentCompleted) {
+		return nil
+	}
+
+	transactionType := "CREDIT"
+	cents := utils.AmountCents(record.Amount)
+	if o.statement.Debit {
+		transactionType = "DEBIT"
+		cents = -cents
+	}
+	o.balance += cents
+
+	fitID := record.EndToEndID
+	if fitID == "" {
+		fitID = record.ID
+	}
+	name := record.PayerName
+	if o.statement.Debit {
+		name = record.PayeeName
+	}
+	memo := record.Description
+	if memo == "" {
+		memo = "Pix " + record.ID
+	}
+
+	fmt.Fprintf(o.writer, "<STMTTRN><TRNTYPE>%s</TRNTYPE><DTPOSTED>%s</DTPOSTED><TRNAMT>%s</TRNAMT><FITID>%s</FITID>",
+		transactionType, ofxTime(postedAt(record)), utils.FormatDecimal(float64(cents)), escape(fitID))
+	if name != "" {
+		fmt.Fprintf(o.writer, "<NAME>%s</NAME>", escape(truncate(name, 32)))
+	}
+	fmt.Fprintf(o.writer, "<MEMO>%s</MEMO></STMTTRN>\n", escape(truncate(memo, 255)))
+	return nil
+}
+
+func (o *OFXWriter) Close() error {
+	o.writeHeader()
+	fmt.Fprintf(o.writer, "</BANKTRANLIST>\n<LEDGERBAL><BALAMT>%s</BALAMT><DTASOF>%s</DTASOF></LEDGERBAL>\n",
+		utils.FormatDecimal(float64(o.balance)), ofxTime(o.statement.End))
+	o.writer.WriteString("</STMTRS></STMTTRNRS></BANKMSGSRSV1>\n</OFX>\n")
+	return o.writer.Flush()
+}
+
+func (o *OFXWriter) writeHeader() {
+	if o.wroteHeader {
+		return
+	}
+	o.wroteHeader = true
+
+	o.writer.WriteString("<?xml version=\"1.0\" encoding=\"UTF-8\" standalone=\"no\"?>\n")
+	o.writer.WriteString("<?OFX OFXHEADER=\"200\" VERSION=\"220\" SECURITY=\"NONE\" OLDFILEUID=\"NONE\" NEWFILEUID=\"NONE\"?>\n")
+	o.writer.WriteString("<OFX>\n")
+	fmt.Fprintf(o.writer, "<SIGNONMSGSRSV1><SONRS><STATUS><CODE>0</CODE><SEVERITY>INFO</SEVERITY></STATUS><DTSERVER>%s</DTSERVER><LANGUAGE>POR</LANGUAGE></SONRS></SIGNONMSGSRSV1>\n", ofxTime(time.Now()))
+	o.writer.WriteString("<BANKMSGSRSV1><STMTTRNRS><TRNUID>1</TRNUID><STATUS><CODE>0</CODE><SEVERITY>INFO</SEVERITY></STATUS>\n")
+	fmt.Fprintf(o.writer, "<STMTRS><CURDEF>BRL</CURDEF>\n<BANKACCTFROM><BANKID>%s</BANKID><BRANCHID>%s</BRANCHID><ACCTID>%s</ACCTID><ACCTTYPE>%s</ACCTTYPE></BANKACCTFROM>\n",
+		escape(o.statement.BankID), escape(o.statement.BranchID), escape(o.statement.AccountID), escape(o.statement.AccountType))
+	fmt.Fprintf(o.writer, "<BANKTRANLIST><DTSTART>%s</DTSTART><DTEND>%s</DTEND>\n", ofxTime(o.statement.Start), ofxTime(o.statement.End))
+}
+
+// postedAt is when the record was settled: its last update when known.
+func postedAt(record *Record) time.Time {
+	if !record.UpdatedAt.IsZero() {
+		return record.UpdatedAt
+	}
+	return record.CreatedAt
+}
+
+// ofxTime formats t in Brazil time with its offset, e.g.
+// "20240229233000[-3:BRT]".
+func ofxTime(t time.Time) string {
+	t = t.In(utils.BrazilLocation)
+	_, offset := t.Zone()
+	return fmt.Sprintf("%s[%d:BRT]", t.Format("20060102150405"), offset/3600)
+}
+
+func escape(value string) string {
+	var builder strings.Builder
+	_ = xml.EscapeText(&builder, []byte(value))
+	return builder.String()
+}
+
+func truncate(value string, length int) string {
+	runes := []rune(value)
+	if len(runes) <= length {
+		return value
+	}
+	return string(runes[:length])
+}
@@ -0,0 +1,206 @@
+package history
+
+import (
+	"errors"
+	"sort"
+	"sync"
+	"time"
+
+	"iniciador-sdk/iniciador/payments"
+)
+
+var ErrNotFound = errors.New("payment history not found")
+
+// Transition is the moment a payment entered a status. At is the updatedAt
+// of the observed status, or the time it was observed when the API does not
+// send one.
+type Transition struct {
+	Status     payments.PaymentInitiationStatus `json:"status"`
+	At         time.Time                        `json:"at"`
+	ObservedAt time.Time                        `json:"observedAt"`
+}
+
+// Timeline is the status history of a payment, oldest transition first.
+type Timeline struct {
+	PaymentID   string       `json:"paymentId"`
+	ExternalID  string       `json:"externalId,omitempty"`
+	EndToEndID  string       `json:"endToEndId,omitempty"`
+	Transitions []Transition `json:"transitions"`
+}
+
+// Current returns the latest transition, or nil for an empty timeline.
+func (t *Timeline) Current() *Transition {
+	if len(t.Transitions) == 0 {
+		return nil
+	}
+	return &t.Transitions[len(t.Transitions)-1]
+}
+
+// Since returns how long the payment has been in its current status.
+func (t *Timeline) Since(now time.Time) time.Duration {
+	current := t.Current()
+	if current == nil {
+		return 0
+	}
+	return now.Sub(current.At)
+}
+
+// Add merges an observed status into the timeline and reports whether it
+// changed. Observations may arrive late or repeated, as when polling and
+// webhooks report the same status: a status already in effect at its time is
+// ignored, and one seen earlier than recorded moves its transition back.
+func (t *Timeline) Add(status *payments.PaymentStatusPayload, observedAt time.Time) bool {
+	changed := false
+	if status.ExternalID != "" && t.ExternalID != status.ExternalID {
+		t.ExternalID = status.ExternalID
+		changed = true
+	}
+	if status.EndToEndID != "" && t.EndToEndID != status.EndToEndID {
+		t.EndToEndID = status.EndToEndID
+		changed = true
+	}
+
+	transition := Transition{
+		Status:     payments.PaymentInitiationStatus(status.Status),
+		At:         status.UpdatedAt.Time,
+		ObservedAt: observedAt,
+	}
+	if transition.Status == "" {
+		return changed
+	}
+	if transition.At.IsZero() {
+		transition.At = observedAt
+	}
+
+	i := sort.Search(len(t.Transitions), func(i int) bool {
+		return t.Transitions[i].At.After(transition.At)
+	})
+	if i > 0 && t.Transitions[i-1].Status == transition.Status {
+		return changed
+	}
+	if i < len(t.Transitions) && t.Transitions[i].Status == transition.Status {
+		t.Transitions[i].At = transition.At
+		return true
+	}
+
+	t.Transitions = append(t.Transitions, Transition{})
+	copy(t.Transitions[i+1:], t.Transitions[i:])
+	t.Transitions[i] = transition
+	return true
+}
+
+func (t *Timeline) clone() *Timeline {
+	timeline := *t
+	timeline.Transitions = append([]Transition(nil), t.Transitions...)
+	return &timeline
+}
+
+// Store keeps the timelines of the observed payments.
+type Store interface {
+	// Observe adds status to the timeline of its payment, creating it.
+	Observe(status *payments.PaymentStatusPayload, observedAt time.Time) error
+	// Timeline returns the timeline of paymentID, or ErrNotFound.
+	Timeline(paymentID string) (*Timeline, error)
+	// List returns the timelines whose current status is any of statuses, or
+	// all timelines when none is given.
+	List(statuses ...payments.PaymentInitiationStatus) ([]*Timeline, error)
+}
+
+// MemoryStore is a Store for a single process.
+type MemoryStore struct {
+	mutex     sync.Mutex
+	timelines map[string]*Timeline
+}
+
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{timelines: make(map[string]*Timeline)}
+}
+
+func (s *MemoryStore) Observe(status *payments.PaymentStatusPayload, observedAt time.Time) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	timeline, ok := s.timelines[status.ID]
+	if !ok {
+		timeline = &Timeline{PaymentID: status.ID}
+		s.timelines[status.ID] = timeline
+	}
+	timeline.Add(status, observedAt)
+	return nil
+}
+
+func (s *MemoryStore) Timeline(paymentID string) (*Timeline, error) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	timeline, ok := s.timelines[paymentID]
+	if !ok {
+		return nil, ErrNotFound
+	}
+	return timeline.clone(), nil
+}
+
+func (s *MemoryStore) List(statuses ...payments.PaymentInitiationStatus) ([]*Timeline, error) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	var timelines []*Timeline
+	for _, timeline := range s.timelines {
+		if hasStatus(timeline, statuses) {
+			timelines = append(timelines, timeline.clone())
+		}
+	}
+	sort.Slice(timelines, func(i, j int) bool {
+		return timelines[i].PaymentID < timelines[j].PaymentID
+	})
+	return timelines, nil
+}
+
+// Observer returns a payments.StatusObserver recording every observed status
+// in store. Errors of the store are passed to onError, when set.
+//
+//	payments.EnableStatusObserver(history.Observer(store, nil))
+func Observer(store Store, onError func(err error)) payments.StatusObserver {
+	return func(status *payments.PaymentStatusPayload) {
+		if status.ID == "" {
+			return
+		}
+		err := store.Observe(status, time.Now())
+		if err != nil && onError != nil {
+			onError(err)
+		}
+	}
+}
+
+// Stuck returns the timelines that have been in status for longer than
+// duration at now, longest first.
+func Stuck(store Store, status payments.PaymentInitiationStatus, duration time.Duration, now time.Time) ([]*Timeline, error) {
+	timelines, err := store.List(status)
+	if err != nil {
+		return nil, err
+	}
+
+	var stuck []*Timeline
+	for _, timeline := range timelines {
+		if timeline.Since(now) > duration {
+			stuck = append(stuck, timeline)
+		}
+	}
+	sort.SliceStable(stuck, func(i, j int) bool {
+		return stuck[i].Current().At.Before(stuck[j].Current().At)
+	})
+	return stuck, nil
+}
+
+func hasStatus(timeline *Timeline, statuses []payments.PaymentInitiationStatus) bool {
+	if len(statuses) == 0 {
+		return true
+	}
+	current := timeline.Current()
+	if current == nil {
+		return false
+	}
+	for _, status := range statuses {
+		if current.Status == status {
+			return true
+		}
+	}
+	return false
+}
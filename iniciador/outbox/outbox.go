@@ -0,0 +1,338 @@
+package outbox
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	"iniciador-sdk/iniciador/auth"
+	"iniciador-sdk/iniciador/payments"
+	"iniciador-sdk/iniciador/utils"
+)
+
+const (
+	DefaultMaxAttempts  = 5
+	DefaultPollInterval = time.Second
+)
+
+type EntryStatus string
+
+const (
+	// Pending entries wait to be sent, possibly again after a failure.
+	Pending EntryStatus = "PENDING"
+	// Sending entries were handed to the API; a process that stops leaves
+	// them in this status, and Recover makes them pending again.
+	Sending EntryStatus = "SENDING"
+	// Sent entries were accepted by the API; Result holds the payment.
+	Sent EntryStatus = "SENT"
+	// Failed entries were refused as invalid, locally or by a client error
+	// response of the API. No payment was initiated.
+	Failed EntryStatus = "FAILED"
+	// Unknown entries ran out of attempts without a definitive answer, after
+	// server errors, timeouts or connection errors. The payment may have been
+	// initiated, so never enqueue it again under another key: call Retry to
+	// send it again under the same key, which settles the outcome.
+	Unknown EntryStatus = "UNKNOWN"
+)
+
+// Entry is a payment the application intends to initiate. Key is sent as the
+// idempotency key of every attempt, so an entry initiates at most one
+// payment however many times it is sent.
+type Entry struct {
+	Key           string                             `json:"key"`
+	Payment       *payments.PaymentInitiationPayload `json:"payment"`
+	Status        EntryStatus                        `json:"status"`
+	Attempts      int                                `json:"attempts"`
+	Result        *payments.PaymentInitiationPayload `json:"result,omitempty"`
+	Error         string                             `json:"error,omitempty"`
+	APIError      *utils.APIError                    `json:"apiError,omitempty"`
+	CreatedAt     time.Time                          `json:"createdAt"`
+	UpdatedAt     time.Time                          `json:"updatedAt"`
+	NextAttemptAt time.Time                          `json:"nextAttemptAt,omitempty"`
+}
+
+// clone deep copies the entry, so storages never share it with callers. It
+// fails when the entry cannot be encoded, e.g. for unsupported metadata.
+func (e *Entry) clone() (*Entry, error) {
+	data, err := json.Marshal(e)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode outbox entry %s: %v", e.Key, err)
+	}
+	var entry Entry
+	err = json.Unmarshal(data, &entry)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode outbox entry %s: %v", e.Key, err)
+	}
+	return &entry, nil
+}
+
+// Outbox stores payments before sending them and sends them in the
+// background, so a payment decided on is neither lost nor duplicated when
+// the process stops at any point. Call Run in a goroutine and Enqueue the
+// payments.
+type Outbox struct {
+	Storage    Storage
+	AuthClient *auth.AuthClient
+	// AccessToken returns the token to send the payments with. Defaults to
+	// a new token from AuthClient.Auth.
+	AccessToken func() (string, error)
+
+	// MaxAttempts is how many times an entry is sent before it becomes
+	// unknown.
+	MaxAttempts int
+	// Backoff is the delay before attempt number attempt+1. Defaults to
+	// exponential backoff from one second, up to five minutes.
+	Backoff func(attempt int) time.Duration
+	// PollInterval is how often Run looks for due entries.
+	PollInterval time.Duration
+	// OnResult, when set, is called when an entry is sent, fails or becomes
+	// unknown.
+	OnResult func(entry *Entry)
+
+	wake chan struct{}
+}
+
+func New(storage Storage, authClient *auth.AuthClient) *Outbox {
+	return &Outbox{Storage: storage, AuthClient: authClient, wake: make(chan struct{}, 1)}
+}
+
+// Enqueue validates and stores a payment to be sent under key. When key was
+// already enqueued, the existing entry is returned with ErrDuplicateKey and
+// nothing is stored, so enqueueing again after a crash is safe.
+func (o *Outbox) Enqueue(key string, payment *payments.PaymentInitiationPayload) (*Entry, error) {
+	if key == "" {
+		return nil, fmt.Errorf("outbox key is required")
+	}
+	err := payment.Validate()
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	entry := &Entry{Key: key, Payment: payment, Status: Pending, CreatedAt: now, UpdatedAt: now}
+	err = o.Storage.Insert(entry)
+	if err == ErrDuplicateKey {
+		existing, getErr := o.Storage.Get(key)
+		if getErr != nil {
+			return nil, getErr
+		}
+		return existing, ErrDuplicateKey
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	select {
+	case o.wake <- struct{}{}:
+	default:
+	}
+	return entry, nil
+}
+
+func (o *Outbox) Get(key string) (*Entry, error) {
+	return o.Storage.Get(key)
+}
+
+// Recover makes the entries left sending by a previous process pending
+// again. Their payments may or may not have reached the API; sending them
+// again with the same idempotency key settles which.
+func (o *Outbox) Recover() error {
+	entries, err := o.Storage.List(Sending)
+	if err != nil {
+		return err
+	}
+	for _, entry := range entries {
+		entry.Status = Pending
+		entry.UpdatedAt = time.Now()
+		err = o.Storage.Update(entry)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Retry makes an unknown or failed entry pending again, with new attempts,
+// to be sent under the same key.
+func (o *Outbox) Retry(key string) error {
+	entry, err := o.Storage.Get(key)
+	if err != nil {
+		return err
+	}
+	if entry.Status != Unknown && entry.Status != Failed {
+		return fmt.Errorf("outbox entry %s is %s and cannot be retried", key, entry.Status)
+	}
+
+	entry.Status = Pending
+	entry.Attempts = 0
+	entry.NextAttemptAt = time.Time{}
+	entry.UpdatedAt = time.Now()
+	err = o.Storage.Update(entry)
+	if err != nil {
+		return err
+	}
+
+	select {
+	case o.wake <- struct{}{}:
+	default:
+	}
+	return nil
+}
+
+// Run recovers the in-flight entries and then sends the due entries until ctx
+// is done, returning ctx.Err().
+func (o *Outbox) Run(ctx context.Context) error {
+	err := o.Recover()
+	if err != nil {
+		return err
+	}
+
+	interval := o.PollInterval
+	if interval <= 0 {
+		interval = DefaultPollInterval
+	}
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		err = o.ProcessPending(ctx)
+		if err != nil && ctx.Err() == nil {
+			return err
+		}
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ticker.C:
+		case <-o.wake:
+		}
+	}
+}
+
+// ProcessPending sends the pending entries that are due, oldest first.
+func (o *Outbox) ProcessPending(ctx context.Context) error {
+	entries, err := o.Storage.List(Pending)
+	if err != nil {
+		return err
+	}
+
+	now := time.Now()
+	for _, entry := range entries {
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		if entry.NextAttemptAt.After(now) {
+			continue
+		}
+		err = o.send(entry)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// send marks the entry sending, sends it and records the result. Only
+// storage errors are returned.
+func (o *Outbox) send(entry *Entry) error {
+	entry.Status = Sending
+	entry.Attempts++
+	entry.UpdatedAt = time.Now()
+	err := o.Storage.Update(entry)
+	if err != nil {
+		return err
+	}
+
+	result, sendErr := o.sendPayment(entry)
+	entry.UpdatedAt = time.Now()
+	entry.NextAttemptAt = time.Time{}
+	entry.Error = ""
+	entry.APIError = nil
+
+	if sendErr != nil {
+		entry.Error = sendErr.Error()
+		errors.As(sendErr, &entry.APIError)
+	}
+
+	switch {
+	case sendErr == nil:
+		entry.Status = Sent
+		entry.Result = result
+	case isPermanent(sendErr):
+		entry.Status = Failed
+	case entry.Attempts < o.maxAttempts():
+		entry.Status = Pending
+		entry.NextAttemptAt = entry.UpdatedAt.Add(o.backoff(entry.Attempts))
+	default:
+		// Without a definitive answer the payment may have been initiated.
+		entry.Status = Unknown
+	}
+
+	err = o.Storage.Update(entry)
+	if err != nil {
+		return err
+	}
+	if entry.Status != Pending && o.OnResult != nil {
+		o.OnResult(entry)
+	}
+	return nil
+}
+
+func (o *Outbox) sendPayment(entry *Entry) (*payments.PaymentInitiationPayload, error) {
+	accessToken, err := o.accessToken()
+	if err != nil {
+		return nil, err
+	}
+	return payments.Send(accessToken, entry.Payment, o.AuthClient, payments.WithIdempotencyKey(entry.Key))
+}
+
+func (o *Outbox) accessToken() (string, error) {
+	if o.AccessToken != nil {
+		return o.AccessToken()
+	}
+	output, err := o.AuthClient.Auth()
+	if err != nil {
+		return "", err
+	}
+	return output.AccessToken, nil
+}
+
+func (o *Outbox) maxAttempts() int {
+	if o.MaxAttempts > 0 {
+		return o.MaxAttempts
+	}
+	return DefaultMaxAttempts
+}
+
+func (o *Outbox) backoff(attempt int) time.Duration {
+	if o.Backoff != nil {
+		return o.Backoff(attempt)
+	}
+	delay := time.Second << uint(attempt-1)
+	if delay <= 0 || delay > 5*time.Minute {
+		return 5 * time.Minute
+	}
+	return delay
+}
+
+// isPermanent reports whether retrying cannot succeed: validation errors and
+// client errors other than timeouts, conflicts and rate limiting.
+func isPermanent(err error) bool {
+	var validationErrors payments.ValidationErrors
+	if errors.As(err, &validationErrors) {
+		return true
+	}
+	var apiError *utils.APIError
+	if !errors.As(err, &apiError) {
+		return false
+	}
+	switch apiError.StatusCode {
+	case http.StatusRequestTimeout, http.StatusConflict, http.StatusTooManyRequests:
+		return false
+	default:
+		return apiError.StatusCode >= 400 && apiError.StatusCode < 500
+	}
+}
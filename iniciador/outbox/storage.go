@@ -0,0 +1,254 @@
+package outbox
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+)
+
+var (
+	ErrDuplicateKey = errors.New("outbox entry key already exists")
+	ErrNotFound     = errors.New("outbox entry not found")
+)
+
+// Storage persists outbox entries. Implementations must make Insert and
+// Update durable before returning, as the outbox relies on them to recover.
+type Storage interface {
+	// Insert stores a new entry, or returns ErrDuplicateKey.
+	Insert(entry *Entry) error
+	// Get returns the entry of key, or ErrNotFound.
+	Get(key string) (*Entry, error)
+	// Update replaces a stored entry, or returns ErrNotFound.
+	Update(entry *Entry) error
+	// List returns the entries with any of the statuses, or all entries when
+	// none is given, oldest first.
+	List(statuses ...EntryStatus) ([]*Entry, error)
+}
+
+// MemoryStorage keeps entries in memory. It is not durable and is meant for
+// tests and for processes that accept losing unsent payments.
+type MemoryStorage struct {
+	mutex   sync.Mutex
+	entries map[string]*Entry
+}
+
+func NewMemoryStorage() *MemoryStorage {
+	return &MemoryStorage{entries: make(map[string]*Entry)}
+}
+
+func (s *MemoryStorage) Insert(entry *Entry) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	if _, ok := s.entries[entry.Key]; ok {
+		return ErrDuplicateKey
+	}
+	return s.store(entry)
+}
+
+func (s *MemoryStorage) Get(key string) (*Entry, error) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	entry, ok := s.entries[key]
+	if !ok {
+		return nil, ErrNotFound
+	}
+	return entry.clone()
+}
+
+func (s *MemoryStorage) Update(entry *Entry) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	if _, ok := s.entries[entry.Key]; !ok {
+		return ErrNotFound
+	}
+	return s.store(entry)
+}
+
+func (s *MemoryStorage) store(entry *Entry) error {
+	stored, err := entry.clone()
+	if err != nil {
+		return err
+	}
+	s.entries[entry.Key] = stored
+	return nil
+}
+
+func (s *MemoryStorage) List(statuses ...EntryStatus) ([]*Entry, error) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	var entries []*Entry
+	for _, entry := range s.entries {
+		if hasStatus(entry, statuses) {
+			listed, err := entry.clone()
+			if err != nil {
+				return nil, err
+			}
+			entries = append(entries, listed)
+		}
+	}
+	sortEntries(entries)
+	return entries, nil
+}
+
+// FileStorage keeps each entry as a JSON file in a directory. Writes go to a
+// temporary file that is synced and renamed over the entry, and the directory
+// is synced after the rename, so an entry is never left half written or lost.
+// It is safe for a single process.
+type FileStorage struct {
+	dir   string
+	mutex sync.Mutex
+}
+
+// NewFileStorage creates dir if needed and returns a FileStorage in it.
+func NewFileStorage(dir string) (*FileStorage, error) {
+	err := os.MkdirAll(dir, 0700)
+	if err != nil {
+		return nil, err
+	}
+	return &FileStorage{dir: dir}, nil
+}
+
+func (s *FileStorage) Insert(entry *Entry) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	_, err := os.Stat(s.path(entry.Key))
+	if err == nil {
+		return ErrDuplicateKey
+	}
+	if !os.IsNotExist(err) {
+		return err
+	}
+	return s.write(entry)
+}
+
+func (s *FileStorage) Get(key string) (*Entry, error) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	return s.read(s.path(key))
+}
+
+func (s *FileStorage) Update(entry *Entry) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	_, err := os.Stat(s.path(entry.Key))
+	if os.IsNotExist(err) {
+		return ErrNotFound
+	}
+	if err != nil {
+		return err
+	}
+	return s.write(entry)
+}
+
+func (s *FileStorage) List(statuses ...EntryStatus) ([]*Entry, error) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	files, err := ioutil.ReadDir(s.dir)
+	if err != nil {
+		return nil, err
+	}
+	var entries []*Entry
+	for _, file := range files {
+		if file.IsDir() || !strings.HasSuffix(file.Name(), ".json") {
+			continue
+		}
+		entry, err := s.read(filepath.Join(s.dir, file.Name()))
+		if err != nil {
+			return nil, err
+		}
+		if hasStatus(entry, statuses) {
+			entries = append(entries, entry)
+		}
+	}
+	sortEntries(entries)
+	return entries, nil
+}
+
+// path names the file of key with its base64url form, so any key is a valid
+// file name.
+func (s *FileStorage) path(key string) string {
+	return filepath.Join(s.dir, base64.RawURLEncoding.EncodeToString([]byte(key))+".json")
+}
+
+func (s *FileStorage) read(path string) (*Entry, error) {
+	data, err := ioutil.ReadFile(path)
+	if os.IsNotExist(err) {
+		return nil, ErrNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	var entry Entry
+	err = json.Unmarshal(data, &entry)
+	if err != nil {
+		return nil, err
+	}
+	return &entry, nil
+}
+
+func (s *FileStorage) write(entry *Entry) error {
+	data, err := json.Marshal(entry)
+	if err != nil {
+		return err
+	}
+
+	file, err := ioutil.TempFile(s.dir, ".entry-*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(file.Name())
+
+	_, err = file.Write(data)
+	if err == nil {
+		err = file.Sync()
+	}
+	if closeErr := file.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return err
+	}
+	err = os.Rename(file.Name(), s.path(entry.Key))
+	if err != nil {
+		return err
+	}
+
+	// Sync the directory too, so that the rename survives a crash.
+	dir, err := os.Open(s.dir)
+	if err != nil {
+		return err
+	}
+	err = dir.Sync()
+	if closeErr := dir.Close(); err == nil {
+		err = closeErr
+	}
+	return err
+}
+
+func hasStatus(entry *Entry, statuses []EntryStatus) bool {
+	if len(statuses) == 0 {
+		return true
+	}
+	for _, status := range statuses {
+		if entry.Status == status {
+			return true
+		}
+	}
+	return false
+}
+
+func sortEntries(entries []*Entry) {
+	sort.SliceStable(entries, func(i, j int) bool {
+		if entries[i].CreatedAt.Equal(entries[j].CreatedAt) {
+			return entries[i].Key < entries[j].Key
+		}
+		return entries[i].CreatedAt.Before(entries[j].CreatedAt)
+	})
+}
@@ -0,0 +1,153 @@
+package participants
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"iniciador-sdk/iniciador/auth"
+)
+
+// DefaultCollectLimit is the cap of CollectAll when none is given.
+const DefaultCollectLimit = 10000
+
+// ErrLimitExceeded is returned by CollectAll when there are more participants
+// than its cap.
+var ErrLimitExceeded = errors.New("participants exceed the collect limit")
+
+type Direction int
+
+const (
+	// Forward follows the after cursors, starting at filters.AfterCursor.
+	Forward Direction = iota
+	// Backward follows the before cursors, starting at filters.BeforeCursor.
+	Backward
+)
+
+// Iterator walks the pages of GetParticipants, fetching each page only when
+// the previous one is exhausted. Participants are yielded in the order of
+// their page.
+//
+//	it := participants.NewIterator(ctx, accessToken, filters, participants.Forward, authClient)
+//	for it.Next() {
+//		participant := it.Participant()
+//	}
+//	if err := it.Err(); err != nil {
+//	}
+type Iterator struct {
+	ctx         context.Context
+	accessToken string
+	filters     ParticipantsFilter
+	direction   Direction
+	authClient  *auth.AuthClient
+
+	page    []ParticipantsPayload
+	index   int
+	cursor  string
+	fetched bool
+	err     error
+}
+
+// NewIterator returns an iterator over the participants matching filters,
+// which may be nil. Requests are made with ctx, and iteration stops with its
+// error once it is done.
+func NewIterator(ctx context.Context, accessToken string, filters *ParticipantsFilter, direction Direction, authClient *auth.AuthClient) *Iterator {
+	it := &Iterator{ctx: ctx, accessToken: accessToken, direction: direction, authClient: authClient}
+	if filters != nil {
+		it.filters = *filters
+	}
+	if direction == Backward {
+		it.cursor = it.filters.BeforeCursor
+	} else {
+		it.cursor = it.filters.AfterCursor
+	}
+	return it
+}
+
+// Next advances to the next participant, fetching the next page when needed.
+// It returns false when there are no more participants or an error occurred.
+func (it *Iterator) Next() bool {
+	if it.err != nil {
+		return false
+	}
+	it.index++
+	for it.index >= len(it.page) {
+		if it.fetched && it.cursor == "" {
+			return false
+		}
+		if !it.fetch() {
+			return false
+		}
+	}
+	return true
+}
+
+// Participant returns the current participant.
+func (it *Iterator) Participant() *ParticipantsPayload {
+	if it.index < 0 || it.index >= len(it.page) {
+		return nil
+	}
+	return &it.page[it.index]
+}
+
+// Err returns the error that stopped the iteration, if any.
+func (it *Iterator) Err() error {
+	return it.err
+}
+
+func (it *Iterator) fetch() bool {
+	err := it.ctx.Err()
+	if err != nil {
+		it.err = err
+		return false
+	}
+
+	filters := it.filters
+	filters.AfterCursor = ""
+	filters.BeforeCursor = ""
+	if it.direction == Backward {
+		filters.BeforeCursor = it.cursor
+	} else {
+		filters.AfterCursor = it.cursor
+	}
+
+	output, err := getParticipants(it.ctx, it.accessToken, &filters, it.authClient)
+	if err != nil {
+		it.err = err
+		return false
+	}
+
+	next := output.Cursor.AfterCursor
+	if it.direction == Backward {
+		next = output.Cursor.BeforeCursor
+	}
+	if next != "" && next == it.cursor {
+		it.err = fmt.Errorf("participants cursor %s did not advance", next)
+		return false
+	}
+
+	it.page = output.Data
+	it.index = 0
+	it.cursor = next
+	it.fetched = true
+	return len(it.page) > 0 || next != ""
+}
+
+// CollectAll returns all the participants matching filters, following the
+// after cursors. When there are more than limit participants, the first limit
+// are returned with ErrLimitExceeded. A limit of zero means DefaultCollectLimit.
+func CollectAll(ctx context.Context, accessToken string, filters *ParticipantsFilter, authClient *auth.AuthClient, limit int) ([]ParticipantsPayload, error) {
+	if limit <= 0 {
+		limit = DefaultCollectLimit
+	}
+
+	var all []ParticipantsPayload
+	it := NewIterator(ctx, accessToken, filters, Forward, authClient)
+	for it.Next() {
+		if len(all) == limit {
+			return all, ErrLimitExceeded
+		}
+		all = append(all, *it.Participant())
+	}
+	return all, it.Err()
+}
@@ -1,18 +1,48 @@
 package participants
 
 import (
+	"context"
+	"errors"
 	"fmt"
 	"iniciador-sdk/iniciador/auth"
 	"iniciador-sdk/iniciador/utils"
 	"net/http"
 	"net/url"
+	"strconv"
 )
 
+// MaxLimit is the largest page size accepted by the API.
+const MaxLimit = 100
+
+// ErrInvalidFilter is wrapped by the errors of ParticipantsFilter.Validate.
+var ErrInvalidFilter = errors.New("invalid participants filter")
+
+type ParticipantStatus string
+
+const (
+	ParticipantActive   ParticipantStatus = "ACTIVE"
+	ParticipantInactive ParticipantStatus = "INACTIVE"
+)
+
+func (s ParticipantStatus) IsValid() bool {
+	switch s {
+	case ParticipantActive, ParticipantInactive:
+		return true
+	default:
+		return false
+	}
+}
+
 type ParticipantsPayload struct {
+	utils.UnknownFields `json:"-"`
+
 	ID     string `json:"id"`
 	Slug   string `json:"slug"`
 	Name   string `json:"name"`
 	Avatar string `json:"avatar"`
+	// Status and ISPB are sent by the API for the participants that have them.
+	Status ParticipantStatus `json:"status,omitempty"`
+	ISPB   string            `json:"ispb,omitempty"`
 }
 
 type Cursor struct {
@@ -25,22 +55,45 @@ type ParticipantFilterOutput struct {
 	Cursor Cursor                `json:"cursor"`
 }
 
+// ParticipantsFilter narrows GetParticipants. Zero values are not sent, so a
+// zero Limit leaves the page size to the API, and a nil FirstParticipants
+// sends neither true nor false.
 type ParticipantsFilter struct {
 	ID                string
 	Name              string
 	Slug              string
-	Status            string
-	FirstParticipants string
-	Limit             string
+	Status            ParticipantStatus
+	FirstParticipants *bool
+	Limit             int
 	AfterCursor       string
 	BeforeCursor      string
 }
 
+// Validate checks the filter before it is sent.
+func (f *ParticipantsFilter) Validate() error {
+	if f.Limit < 0 || f.Limit > MaxLimit {
+		return fmt.Errorf("%w: limit must be between 1 and %d, or 0 for the API default, got %d", ErrInvalidFilter, MaxLimit, f.Limit)
+	}
+	if f.Status != "" && !f.Status.IsValid() {
+		return fmt.Errorf("%w: invalid status %q", ErrInvalidFilter, f.Status)
+	}
+	return nil
+}
+
 func GetParticipants(accessToken string, filters *ParticipantsFilter, authClient *auth.AuthClient) (*ParticipantFilterOutput, error) {
+	return getParticipants(context.Background(), accessToken, filters, authClient)
+}
+
+func getParticipants(ctx context.Context, accessToken string, filters *ParticipantsFilter, authClient *auth.AuthClient) (*ParticipantFilterOutput, error) {
 	environment := authClient.GetEnvironment()
 	filterParams := make(url.Values)
 
 	if filters != nil {
+		err := filters.Validate()
+		if err != nil {
+			return nil, err
+		}
+
 		if filters.ID != "" {
 			filterParams.Set("id", filters.ID)
 		}
@@ -51,13 +104,13 @@ func GetParticipants(accessToken string, filters *ParticipantsFilter, authClient
 			filterParams.Set("slug", filters.Slug)
 		}
 		if filters.Status != "" {
-			filterParams.Set("status", filters.Status)
+			filterParams.Set("status", string(filters.Status))
 		}
-		if filters.FirstParticipants != "" {
-			filterParams.Set("firstParticipants", filters.FirstParticipants)
+		if filters.FirstParticipants != nil {
+			filterParams.Set("firstParticipants", strconv.FormatBool(*filters.FirstParticipants))
 		}
-		if filters.Limit != "" {
-			filterParams.Set("limit", filters.Limit)
+		if filters.Limit != 0 {
+			filterParams.Set("limit", strconv.Itoa(filters.Limit))
 		}
 		if filters.AfterCursor != "" {
 			filterParams.Set("afterCursor", filters.AfterCursor)
@@ -74,7 +127,7 @@ func GetParticipants(accessToken string, filters *ParticipantsFilter, authClient
 
 	url := fmt.Sprintf("%s/participants?%s", environment, queryString)
 
-	req, err := http.NewRequest(http.MethodGet, url, nil)
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
 	if err != nil {
 		return nil, err
 	}
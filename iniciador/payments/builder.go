@@ -0,0 +1,130 @@
+package payments
+
+import "iniciador-sdk/iniciador/utils"
+
+// Builder assembles a PaymentInitiationPayload for one of the initiation
+// flows. Start with ToPixKey, ToQRCode, ToDynamicQRCode, ToAccount or
+// ToInitiatorAccount, which take the fields the flow requires, chain the
+// remaining fields and call Build:
+//
+//	payment, err := payments.ToPixKey("john@example.com").
+//		Amount(1500).
+//		Payer(payments.User{Name: "Jane Doe", TaxID: "52998224725"}).
+//		Description("Order 42").
+//		Build()
+type Builder struct {
+	payment PaymentInitiationPayload
+}
+
+// ToPixKey starts a DICT payment to a Pix key.
+func ToPixKey(pixKey string) *Builder {
+	return &Builder{payment: PaymentInitiationPayload{Method: PixDict, PixKey: pixKey}}
+}
+
+// ToQRCode starts a payment of a static QR code (Pix copia e cola).
+func ToQRCode(qrCode string) *Builder {
+	return &Builder{payment: PaymentInitiationPayload{Method: PixStaticQRCode, QRCode: qrCode}}
+}
+
+// ToDynamicQRCode starts a payment of a dynamic QR code.
+func ToDynamicQRCode(qrCode string) *Builder {
+	return &Builder{payment: PaymentInitiationPayload{Method: PixDynamicQRCode, QRCode: qrCode}}
+}
+
+// ToAccount starts a payment to a creditor account typed in manually.
+func ToAccount(creditor BankAccount) *Builder {
+	return &Builder{payment: PaymentInitiationPayload{Method: PixManual, Creditor: &creditor}}
+}
+
+// ToInitiatorAccount starts a payment to a creditor account provided by the
+// initiator.
+func ToInitiatorAccount(creditor BankAccount) *Builder {
+	return &Builder{payment: PaymentInitiationPayload{Method: PixInitiator, Creditor: &creditor}}
+}
+
+// Amount sets the amount in cents.
+func (b *Builder) Amount(amount float64) *Builder {
+	b.payment.Amount = amount
+	return b
+}
+
+// Payer sets the user paying; its TaxID is required.
+func (b *Builder) Payer(user User) *Builder {
+	b.payment.User = user
+	return b
+}
+
+// BusinessEntity sets the company on whose behalf the user pays.
+func (b *Builder) BusinessEntity(entity User) *Builder {
+	b.payment.BusinessEntity = &entity
+	return b
+}
+
+// DebtorAccount sets the account the payment is taken from.
+func (b *Builder) DebtorAccount(debtor BankAccount) *Builder {
+	b.payment.Debtor = &debtor
+	return b
+}
+
+// Participant sets the participant (bank) the user authorizes the payment in.
+func (b *Builder) Participant(participantID string) *Builder {
+	b.payment.ParticipantID = participantID
+	return b
+}
+
+func (b *Builder) ExternalID(externalID string) *Builder {
+	b.payment.ExternalID = externalID
+	return b
+}
+
+func (b *Builder) Description(description string) *Builder {
+	b.payment.Description = description
+	return b
+}
+
+// Metadata adds the given entries to the payment metadata.
+func (b *Builder) Metadata(metadata Metadata) *Builder {
+	if b.payment.Metadata == nil {
+		b.payment.Metadata = make(Metadata, len(metadata))
+	}
+	for key, value := range metadata {
+		b.payment.Metadata[key] = value
+	}
+	return b
+}
+
+// RedirectURLs sets where the user is sent after authorizing the payment and
+// after an error. Either can be empty.
+func (b *Builder) RedirectURLs(redirectURL, redirectOnErrorURL string) *Builder {
+	b.payment.RedirectURL = redirectURL
+	b.payment.RedirectOnErrorURL = redirectOnErrorURL
+	return b
+}
+
+func (b *Builder) Schedule(schedule *Schedule) *Builder {
+	b.payment.Schedule = schedule
+	return b
+}
+
+// ScheduleOn schedules a single payment on date.
+func (b *Builder) ScheduleOn(date utils.Date) *Builder {
+	return b.Schedule(&Schedule{Single: &SingleSchedule{Date: date}})
+}
+
+// Build validates the payment and returns it. The builder can keep being used
+// afterwards; later changes do not affect the returned payment.
+func (b *Builder) Build() (*PaymentInitiationPayload, error) {
+	payment := b.payment
+	if b.payment.Metadata != nil {
+		payment.Metadata = make(Metadata, len(b.payment.Metadata))
+		for key, value := range b.payment.Metadata {
+			payment.Metadata[key] = value
+		}
+	}
+
+	err := payment.Validate()
+	if err != nil {
+		return nil, err
+	}
+	return &payment, nil
+}
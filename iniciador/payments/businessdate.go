@@ -0,0 +1,27 @@
+package payments
+
+import "iniciador-sdk/iniciador/utils"
+
+// BusinessDate returns the Brazilian business date of the payment: its
+// scheduled date or, when not scheduled, the date it was created in
+// America/Sao_Paulo, moved forward to the next business day when needed.
+func (p *PaymentInitiationPayload) BusinessDate() utils.Date {
+	return businessDate(p.Date, p.CreatedAt)
+}
+
+// BusinessDate returns the Brazilian business date of the payment: its
+// scheduled date or, when not scheduled, the date it was created in
+// America/Sao_Paulo, moved forward to the next business day when needed.
+func (p *PaymentStatusPayload) BusinessDate() utils.Date {
+	return businessDate(p.Date, p.CreatedAt)
+}
+
+func businessDate(date utils.Date, createdAt utils.Timestamp) utils.Date {
+	if date.IsZero() {
+		date = createdAt.BrazilDate()
+	}
+	if date.IsZero() {
+		return date
+	}
+	return utils.NextBusinessDay(date)
+}
@@ -0,0 +1,36 @@
+package payments
+
+import "sync"
+
+// StatusObserver receives every payment status the SDK observes, whether
+// fetched by Status or received by a webhook.
+type StatusObserver func(status *PaymentStatusPayload)
+
+var statusObserver struct {
+	sync.RWMutex
+	observer StatusObserver
+}
+
+// EnableStatusObserver makes the SDK call observer with each payment status
+// it observes. See the history package for an observer that records them.
+func EnableStatusObserver(observer StatusObserver) {
+	statusObserver.Lock()
+	defer statusObserver.Unlock()
+	statusObserver.observer = observer
+}
+
+func DisableStatusObserver() {
+	EnableStatusObserver(nil)
+}
+
+// ObserveStatus passes status to the enabled observer, if any. Code that
+// learns of payment statuses outside the SDK can call it too.
+func ObserveStatus(status *PaymentStatusPayload) {
+	statusObserver.RLock()
+	observer := statusObserver.observer
+	statusObserver.RUnlock()
+
+	if observer != nil && status != nil {
+		observer(status)
+	}
+}
@@ -57,6 +57,31 @@ const (
 	PaymentScheduled               PaymentInitiationStatus = "PAYMENT_SCHEDULED"
 )
 
+// IsFinal reports whether the payment can no longer change status.
+func (s PaymentInitiationStatus) IsFinal() bool {
+	switch s {
+	case ConsentRejected, PaymentCompleted, PaymentRejected, Canceled, Err:
+		return true
+	default:
+		return false
+	}
+}
+
+// IsFailure reports whether the payment ended without money being moved.
+func (s PaymentInitiationStatus) IsFailure() bool {
+	return s.IsFinal() && s != PaymentCompleted
+}
+
+type PaymentMethod = utils.PaymentMethod
+
+const (
+	PixManual        = utils.PixManual
+	PixDict          = utils.PixDict
+	PixStaticQRCode  = utils.PixStaticQRCode
+	PixDynamicQRCode = utils.PixDynamicQRCode
+	PixInitiator     = utils.PixInitiator
+)
+
 type Provider struct {
 	TradeName string `json:"tradeName"`
 	Avatar    string `json:"avatar"`
@@ -66,8 +91,11 @@ type Provider struct {
 type Metadata map[string]interface{}
 
 type PaymentInitiationPayload struct {
+	utils.UnknownFields `json:"-"`
+
 	ID                        string                   `json:"id"`
-	CreatedAt                 string                   `json:"createdAt"`
+	CreatedAt                 utils.Timestamp          `json:"createdAt"`
+	UpdatedAt                 utils.Timestamp          `json:"updatedAt,omitempty"`
 	Error                     *Error                   `json:"error,omitempty"`
 	Status                    *PaymentInitiationStatus `json:"status,omitempty"`
 	ExternalID                string                   `json:"externalId,omitempty"`
@@ -81,11 +109,11 @@ type PaymentInitiationPayload struct {
 	ParticipantID             string                   `json:"participantId,omitempty"`
 	User                      User                     `json:"user"`
 	BusinessEntity            *User                    `json:"businessEntity,omitempty"`
-	Method                    string                   `json:"method"`
+	Method                    PaymentMethod            `json:"method"`
 	PixKey                    string                   `json:"pixKey,omitempty"`
 	QRCode                    string                   `json:"qrCode,omitempty"`
 	Amount                    float64                  `json:"amount"`
-	Date                      string                   `json:"date"`
+	Date                      utils.Date               `json:"date"`
 	Description               string                   `json:"description,omitempty"`
 	Metadata                  Metadata                 `json:"metadata,omitempty"`
 	RedirectURL               string                   `json:"redirectURL,omitempty"`
@@ -94,6 +122,7 @@ type PaymentInitiationPayload struct {
 	Debtor                    *BankAccount             `json:"debtor,omitempty"`
 	Creditor                  *BankAccount             `json:"creditor,omitempty"`
 	Fee                       float64                  `json:"fee,omitempty"`
+	Schedule                  *Schedule                `json:"schedule,omitempty"`
 }
 
 type Error struct {
@@ -102,34 +131,86 @@ type Error struct {
 }
 
 type PaymentStatusPayload struct {
-	ID                        string  `json:"id"`
-	Date                      string  `json:"date"`
-	ConsentID                 string  `json:"consentId,omitempty"`
-	CreatedAt                 string  `json:"createdAt"`
-	UpdatedAt                 string  `json:"updatedAt"`
-	TransactionIdentification string  `json:"transactionIdentification,omitempty"`
-	EndToEndID                string  `json:"endToEndId,omitempty"`
-	Amount                    float64 `json:"amount"`
-	Status                    string  `json:"status"`
-	Error                     *Error  `json:"error,omitempty"`
-	RedirectConsentURL        string  `json:"redirectConsentURL,omitempty"`
-	ExternalID                string  `json:"externalId"`
-}
-
-func Send(accessToken string, payment *PaymentInitiationPayload, authClient *auth.AuthClient) (*PaymentInitiationPayload, error) {
-	payload, err := utils.MarshalWithoutEmptyFields(payment)
+	utils.UnknownFields `json:"-"`
+
+	ID                        string          `json:"id"`
+	Date                      utils.Date      `json:"date"`
+	ConsentID                 string          `json:"consentId,omitempty"`
+	CreatedAt                 utils.Timestamp `json:"createdAt"`
+	UpdatedAt                 utils.Timestamp `json:"updatedAt"`
+	TransactionIdentification string          `json:"transactionIdentification,omitempty"`
+	EndToEndID                string          `json:"endToEndId,omitempty"`
+	Amount                    float64         `json:"amount"`
+	Status                    string          `json:"status"`
+	Error                     *Error          `json:"error,omitempty"`
+	RedirectConsentURL        string          `json:"redirectConsentURL,omitempty"`
+	ExternalID                string          `json:"externalId"`
+	Schedule                  *Schedule       `json:"schedule,omitempty"`
+}
+
+type sendOptions struct {
+	idempotencyKey    string
+	disableValidation bool
+	fields            []string
+}
+
+// SendOption customizes a single Send call.
+type SendOption func(*sendOptions)
+
+// WithIdempotencyKey sends the payment with an Idempotency-Key header, so
+// retrying with the same key never initiates the payment twice.
+func WithIdempotencyKey(key string) SendOption {
+	return func(options *sendOptions) {
+		options.idempotencyKey = key
+	}
+}
+
+// WithoutValidation skips the local Validate call, leaving all validation
+// to the API.
+func WithoutValidation() SendOption {
+	return func(options *sendOptions) {
+		options.disableValidation = true
+	}
+}
+
+// WithFields always sends the given fields, identified by JSON path such as
+// "debtor.number", even when unset. See utils.MarshalWithFields.
+func WithFields(fields ...string) SendOption {
+	return func(options *sendOptions) {
+		options.fields = append(options.fields, fields...)
+	}
+}
+
+func Send(accessToken string, payment *PaymentInitiationPayload, authClient *auth.AuthClient, opts ...SendOption) (*PaymentInitiationPayload, error) {
+	options := &sendOptions{}
+	for _, opt := range opts {
+		opt(options)
+	}
+
+	if payment.Schedule != nil && payment.Schedule.IsRecurring() {
+		return nil, fmt.Errorf("recurring schedules must be sent with SendRecurring")
+	}
+	if !options.disableValidation {
+		err := payment.Validate()
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	payload, err := utils.MarshalWithFields(payment, options.fields...)
 	if err != nil {
 		return nil, err
 	}
 
-	fmt.Println(bytes.NewBuffer(payload))
-
 	req, err := http.NewRequest("POST", authClient.Environment+"/payments", bytes.NewBuffer(payload))
 	if err != nil {
 		return nil, err
 	}
 	req.Header.Set("Content-Type", "application/json")
 	req.Header.Set("Authorization", "Bearer "+accessToken)
+	if options.idempotencyKey != "" {
+		req.Header.Set("Idempotency-Key", options.idempotencyKey)
+	}
 
 	client := &http.Client{}
 	response, err := client.Do(req)
@@ -150,7 +231,6 @@ func Send(accessToken string, payment *PaymentInitiationPayload, authClient *aut
 func Get(accessToken string, authClient *auth.AuthClient) (*PaymentInitiationPayload, error) {
 	paymentId, err := utils.ExtractPaymentIDFromJWTPayload(accessToken)
 	if err != nil {
-		fmt.Println("Something went wrong trying to get token data:", err)
 		return nil, err
 	}
 
@@ -180,7 +260,6 @@ func Get(accessToken string, authClient *auth.AuthClient) (*PaymentInitiationPay
 func Status(accessToken string, authClient *auth.AuthClient) (*PaymentStatusPayload, error) {
 	paymentId, err := utils.ExtractPaymentIDFromJWTPayload(accessToken)
 	if err != nil {
-		fmt.Println("Something went wrong trying to get token data:", err)
 		return nil, err
 	}
 
@@ -203,6 +282,7 @@ func Status(accessToken string, authClient *auth.AuthClient) (*PaymentStatusPayl
 	if err != nil {
 		return nil, err
 	}
+	ObserveStatus(&payload)
 
 	return &payload, nil
 }
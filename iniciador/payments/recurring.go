@@ -0,0 +1,97 @@
+package payments
+
+import (
+	"fmt"
+	"net/http"
+
+	"iniciador-sdk/iniciador/auth"
+	"iniciador-sdk/iniciador/utils"
+)
+
+// RecurringPaymentPayload is a series of scheduled payments authorized by a
+// single consent. Each occurrence is a regular payment in Payments.
+type RecurringPaymentPayload struct {
+	utils.UnknownFields `json:"-"`
+
+	ID         string                     `json:"id"`
+	CreatedAt  utils.Timestamp            `json:"createdAt"`
+	ConsentID  string                     `json:"consentId,omitempty"`
+	ExternalID string                     `json:"externalId,omitempty"`
+	Status     *PaymentInitiationStatus   `json:"status,omitempty"`
+	Amount     float64                    `json:"amount"`
+	Schedule   *Schedule                  `json:"schedule"`
+	Payments   []PaymentInitiationPayload `json:"payments,omitempty"`
+}
+
+type cancelRequest struct {
+	Status PaymentInitiationStatus `json:"status"`
+}
+
+// SendRecurring creates a recurring series from a payment whose Schedule is
+// daily, weekly, monthly or custom. The payment is validated and the
+// occurrence dates expanded and checked locally before the request is sent.
+func SendRecurring(accessToken string, payment *PaymentInitiationPayload, authClient *auth.AuthClient) (*RecurringPaymentPayload, error) {
+	if payment.Schedule == nil || !payment.Schedule.IsRecurring() {
+		return nil, fmt.Errorf("recurring payments require a daily, weekly, monthly or custom schedule")
+	}
+	err := payment.Validate()
+	if err != nil {
+		return nil, err
+	}
+
+	var output RecurringPaymentPayload
+	url := fmt.Sprintf("%s/payments/recurring", authClient.Environment)
+	err = utils.DoRequest(http.MethodPost, url, accessToken, payment, &output)
+	if err != nil {
+		return nil, err
+	}
+
+	return &output, nil
+}
+
+func GetRecurring(accessToken, recurringID string, authClient *auth.AuthClient) (*RecurringPaymentPayload, error) {
+	var output RecurringPaymentPayload
+	url := fmt.Sprintf("%s/payments/recurring/%s", authClient.Environment, recurringID)
+	err := utils.DoRequest(http.MethodGet, url, accessToken, nil, &output)
+	if err != nil {
+		return nil, err
+	}
+
+	return &output, nil
+}
+
+// GetOccurrence returns a single payment of a recurring series.
+func GetOccurrence(accessToken, recurringID, paymentID string, authClient *auth.AuthClient) (*PaymentInitiationPayload, error) {
+	var output PaymentInitiationPayload
+	url := fmt.Sprintf("%s/payments/recurring/%s/payments/%s", authClient.Environment, recurringID, paymentID)
+	err := utils.DoRequest(http.MethodGet, url, accessToken, nil, &output)
+	if err != nil {
+		return nil, err
+	}
+
+	return &output, nil
+}
+
+// CancelRecurring cancels every pending occurrence of a recurring series.
+func CancelRecurring(accessToken, recurringID string, authClient *auth.AuthClient) (*RecurringPaymentPayload, error) {
+	var output RecurringPaymentPayload
+	url := fmt.Sprintf("%s/payments/recurring/%s", authClient.Environment, recurringID)
+	err := utils.DoRequest(http.MethodPatch, url, accessToken, &cancelRequest{Status: Canceled}, &output)
+	if err != nil {
+		return nil, err
+	}
+
+	return &output, nil
+}
+
+// CancelOccurrence cancels a single pending payment of a recurring series.
+func CancelOccurrence(accessToken, recurringID, paymentID string, authClient *auth.AuthClient) (*PaymentInitiationPayload, error) {
+	var output PaymentInitiationPayload
+	url := fmt.Sprintf("%s/payments/recurring/%s/payments/%s", authClient.Environment, recurringID, paymentID)
+	err := utils.DoRequest(http.MethodPatch, url, accessToken, &cancelRequest{Status: Canceled}, &output)
+	if err != nil {
+		return nil, err
+	}
+
+	return &output, nil
+}
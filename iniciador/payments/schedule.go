@@ -0,0 +1,296 @@
+package payments
+
+import (
+	"fmt"
+	"time"
+
+	"iniciador-sdk/iniciador/utils"
+)
+
+const (
+	// MaxScheduleDays is how far ahead, in days, a Pix payment can be scheduled.
+	MaxScheduleDays = 365
+	// MaxRecurringScheduleDays is how far ahead, in days, the last occurrence
+	// of a recurring schedule can be.
+	MaxRecurringScheduleDays = 730
+	MinRecurringQuantity     = 2
+	MaxDailyQuantity         = 60
+	MaxWeeklyQuantity        = 60
+	MaxMonthlyQuantity       = 24
+	MaxCustomDates           = 60
+)
+
+type DayOfWeek string
+
+const (
+	Monday    DayOfWeek = "SEGUNDA_FEIRA"
+	Tuesday   DayOfWeek = "TERCA_FEIRA"
+	Wednesday DayOfWeek = "QUARTA_FEIRA"
+	Thursday  DayOfWeek = "QUINTA_FEIRA"
+	Friday    DayOfWeek = "SEXTA_FEIRA"
+	Saturday  DayOfWeek = "SABADO"
+	Sunday    DayOfWeek = "DOMINGO"
+)
+
+var weekdays = map[DayOfWeek]time.Weekday{
+	Monday:    time.Monday,
+	Tuesday:   time.Tuesday,
+	Wednesday: time.Wednesday,
+	Thursday:  time.Thursday,
+	Friday:    time.Friday,
+	Saturday:  time.Saturday,
+	Sunday:    time.Sunday,
+}
+
+type SingleSchedule struct {
+	Date utils.Date `json:"date"`
+}
+
+type DailySchedule struct {
+	StartDate utils.Date `json:"startDate"`
+	Quantity  int        `json:"quantity"`
+}
+
+type WeeklySchedule struct {
+	DayOfWeek DayOfWeek  `json:"dayOfWeek"`
+	StartDate utils.Date `json:"startDate"`
+	Quantity  int        `json:"quantity"`
+}
+
+// MonthlySchedule pays on DayOfMonth; in shorter months the payment falls on
+// the last day of the month.
+type MonthlySchedule struct {
+	DayOfMonth int        `json:"dayOfMonth"`
+	StartDate  utils.Date `json:"startDate"`
+	Quantity   int        `json:"quantity"`
+}
+
+type CustomSchedule struct {
+	Dates                 []utils.Date `json:"dates"`
+	AdditionalInformation string       `json:"additionalInformation,omitempty"`
+}
+
+// Schedule turns a payment initiation into a scheduled (agendado) Pix
+// payment. Exactly one option must be set: Single for a one-off payment, or
+// Daily, Weekly, Monthly or Custom for a recurring series under one consent.
+// Dates are civil dates in the America/Sao_Paulo timezone.
+type Schedule struct {
+	Single  *SingleSchedule  `json:"single,omitempty"`
+	Daily   *DailySchedule   `json:"daily,omitempty"`
+	Weekly  *WeeklySchedule  `json:"weekly,omitempty"`
+	Monthly *MonthlySchedule `json:"monthly,omitempty"`
+	Custom  *CustomSchedule  `json:"custom,omitempty"`
+}
+
+// NewSingleSchedule returns a schedule for a single payment on date,
+// validated against the current date in Brazil.
+func NewSingleSchedule(date utils.Date) (*Schedule, error) {
+	return newSchedule(&Schedule{Single: &SingleSchedule{Date: date}})
+}
+
+func NewDailySchedule(startDate utils.Date, quantity int) (*Schedule, error) {
+	return newSchedule(&Schedule{Daily: &DailySchedule{StartDate: startDate, Quantity: quantity}})
+}
+
+func NewWeeklySchedule(dayOfWeek DayOfWeek, startDate utils.Date, quantity int) (*Schedule, error) {
+	return newSchedule(&Schedule{Weekly: &WeeklySchedule{DayOfWeek: dayOfWeek, StartDate: startDate, Quantity: quantity}})
+}
+
+func NewMonthlySchedule(dayOfMonth int, startDate utils.Date, quantity int) (*Schedule, error) {
+	return newSchedule(&Schedule{Monthly: &MonthlySchedule{DayOfMonth: dayOfMonth, StartDate: startDate, Quantity: quantity}})
+}
+
+func NewCustomSchedule(dates ...utils.Date) (*Schedule, error) {
+	return newSchedule(&Schedule{Custom: &CustomSchedule{Dates: dates}})
+}
+
+func newSchedule(schedule *Schedule) (*Schedule, error) {
+	err := schedule.Validate(utils.TodayInBrazil())
+	if err != nil {
+		return nil, err
+	}
+	return schedule, nil
+}
+
+// IsRecurring reports whether the schedule describes a series of payments.
+func (s *Schedule) IsRecurring() bool {
+	return s.Daily != nil || s.Weekly != nil || s.Monthly != nil || s.Custom != nil
+}
+
+// Validate checks the schedule against today, the current civil date in
+// Brazil: single dates must fall between the next day and MaxScheduleDays
+// ahead, and every recurring occurrence between the next day and
+// MaxRecurringScheduleDays ahead.
+func (s *Schedule) Validate(today utils.Date) error {
+	options := 0
+	for _, isSet := range []bool{s.Single != nil, s.Daily != nil, s.Weekly != nil, s.Monthly != nil, s.Custom != nil} {
+		if isSet {
+			options++
+		}
+	}
+	if options != 1 {
+		return fmt.Errorf("schedule must define exactly one of single, daily, weekly, monthly or custom")
+	}
+
+	if s.Single != nil {
+		return validateScheduleDate(s.Single.Date, today, MaxScheduleDays)
+	}
+
+	occurrences, err := s.Occurrences()
+	if err != nil {
+		return err
+	}
+	for _, date := range occurrences {
+		err = validateScheduleDate(date, today, MaxRecurringScheduleDays)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Occurrences expands the schedule into the ordered list of payment dates.
+func (s *Schedule) Occurrences() ([]utils.Date, error) {
+	switch {
+	case s.Single != nil:
+		return []utils.Date{s.Single.Date}, nil
+	case s.Daily != nil:
+		return s.Daily.occurrences()
+	case s.Weekly != nil:
+		return s.Weekly.occurrences()
+	case s.Monthly != nil:
+		return s.Monthly.occurrences()
+	case s.Custom != nil:
+		return s.Custom.occurrences()
+	default:
+		return nil, fmt.Errorf("schedule is empty")
+	}
+}
+
+func (d *DailySchedule) occurrences() ([]utils.Date, error) {
+	err := validateQuantity("daily", d.Quantity, MaxDailyQuantity)
+	if err != nil {
+		return nil, err
+	}
+	if d.StartDate.IsZero() {
+		return nil, fmt.Errorf("daily schedule start date is required")
+	}
+
+	dates := make([]utils.Date, d.Quantity)
+	for i := range dates {
+		dates[i] = d.StartDate.AddDays(i)
+	}
+	return dates, nil
+}
+
+func (w *WeeklySchedule) occurrences() ([]utils.Date, error) {
+	err := validateQuantity("weekly", w.Quantity, MaxWeeklyQuantity)
+	if err != nil {
+		return nil, err
+	}
+	weekday, ok := weekdays[w.DayOfWeek]
+	if !ok {
+		return nil, fmt.Errorf("invalid weekly schedule day of week %q", w.DayOfWeek)
+	}
+	if w.StartDate.IsZero() {
+		return nil, fmt.Errorf("weekly schedule start date is required")
+	}
+
+	first := w.StartDate.AddDays((int(weekday) - int(w.StartDate.Weekday()) + 7) % 7)
+	dates := make([]utils.Date, w.Quantity)
+	for i := range dates {
+		dates[i] = first.AddDays(7 * i)
+	}
+	return dates, nil
+}
+
+func (m *MonthlySchedule) occurrences() ([]utils.Date, error) {
+	err := validateQuantity("monthly", m.Quantity, MaxMonthlyQuantity)
+	if err != nil {
+		return nil, err
+	}
+	if m.DayOfMonth < 1 || m.DayOfMonth > 31 {
+		return nil, fmt.Errorf("invalid monthly schedule day of month %d", m.DayOfMonth)
+	}
+	if m.StartDate.IsZero() {
+		return nil, fmt.Errorf("monthly schedule start date is required")
+	}
+
+	month := utils.NewDate(m.StartDate.Year, m.StartDate.Month, 1)
+	if dayInMonth(month, m.DayOfMonth).Before(m.StartDate) {
+		month = month.AddMonths(1)
+	}
+	dates := make([]utils.Date, m.Quantity)
+	for i := range dates {
+		dates[i] = dayInMonth(month.AddMonths(i), m.DayOfMonth)
+	}
+	return dates, nil
+}
+
+// dayInMonth returns the given day in the month of firstDay, clamped to the
+// month's last day.
+func dayInMonth(firstDay utils.Date, day int) utils.Date {
+	lastDay := firstDay.AddMonths(1).AddDays(-1)
+	if day > lastDay.Day {
+		day = lastDay.Day
+	}
+	return utils.NewDate(firstDay.Year, firstDay.Month, day)
+}
+
+func (c *CustomSchedule) occurrences() ([]utils.Date, error) {
+	err := validateQuantity("custom", len(c.Dates), MaxCustomDates)
+	if err != nil {
+		return nil, err
+	}
+
+	dates := make([]utils.Date, len(c.Dates))
+	for i, date := range c.Dates {
+		if i > 0 && !date.After(c.Dates[i-1]) {
+			return nil, fmt.Errorf("custom schedule dates must be unique and in ascending order")
+		}
+		dates[i] = date
+	}
+	return dates, nil
+}
+
+func validateQuantity(option string, quantity, max int) error {
+	if quantity < MinRecurringQuantity || quantity > max {
+		return fmt.Errorf("%s schedule must have between %d and %d payments, got %d", option, MinRecurringQuantity, max, quantity)
+	}
+	return nil
+}
+
+func validateScheduleDate(date, today utils.Date, maxDays int) error {
+	if date.IsZero() {
+		return fmt.Errorf("schedule date is required")
+	}
+	if !date.After(today) {
+		return fmt.Errorf("schedule date %s must be after %s", date, today)
+	}
+	if date.DaysSince(today) > maxDays {
+		return fmt.Errorf("schedule date %s must be at most %d days after %s", date, maxDays, today)
+	}
+	return nil
+}
+
+// ScheduledDate returns the date the payment is scheduled for, if any.
+func (p *PaymentInitiationPayload) ScheduledDate() (utils.Date, bool) {
+	isScheduled := p.Status != nil && *p.Status == PaymentScheduled
+	return scheduledDate(p.Schedule, p.Date, isScheduled)
+}
+
+// ScheduledDate returns the date the payment is scheduled for, if any.
+func (p *PaymentStatusPayload) ScheduledDate() (utils.Date, bool) {
+	isScheduled := PaymentInitiationStatus(p.Status) == PaymentScheduled
+	return scheduledDate(p.Schedule, p.Date, isScheduled)
+}
+
+func scheduledDate(schedule *Schedule, date utils.Date, isScheduled bool) (utils.Date, bool) {
+	if schedule != nil && schedule.Single != nil && !schedule.Single.Date.IsZero() {
+		return schedule.Single.Date, true
+	}
+	if !isScheduled || date.IsZero() {
+		return utils.Date{}, false
+	}
+	return date, true
+}
@@ -0,0 +1,199 @@
+package payments
+
+import (
+	"fmt"
+	"math"
+	"net/url"
+	"strings"
+	"unicode/utf8"
+
+	"iniciador-sdk/iniciador/utils"
+)
+
+const MaxDescriptionLength = 140
+
+// Validation rules reported in FieldError.Rule.
+const (
+	RuleRequired  = "required"
+	RulePositive  = "positive"
+	RuleCents     = "cents"
+	RuleDocument  = "document"
+	RuleISPB      = "ispb"
+	RuleBranch    = "branch"
+	RuleAccount   = "account"
+	RuleEnum      = "enum"
+	RuleURL       = "url"
+	RuleMaxLength = "maxLength"
+	RuleSchedule  = "schedule"
+	RuleFormat    = "format"
+)
+
+// FieldError is a validation problem of a single field, identified by its
+// JSON path in the request body, e.g. "creditor.ispb".
+type FieldError struct {
+	Path    string `json:"path"`
+	Rule    string `json:"rule"`
+	Message string `json:"message"`
+}
+
+func (e FieldError) Error() string {
+	return fmt.Sprintf("%s: %s", e.Path, e.Message)
+}
+
+// ValidationErrors is the error returned by Validate, listing every field
+// that failed validation.
+type ValidationErrors []FieldError
+
+func (e ValidationErrors) Error() string {
+	messages := make([]string, len(e))
+	for i, fieldError := range e {
+		messages[i] = fieldError.Error()
+	}
+	return "invalid payment: " + strings.Join(messages, "; ")
+}
+
+type validator struct {
+	errors ValidationErrors
+}
+
+func (v *validator) add(path, rule, format string, args ...interface{}) {
+	v.errors = append(v.errors, FieldError{Path: path, Rule: rule, Message: fmt.Sprintf(format, args...)})
+}
+
+func (v *validator) required(path, value string) bool {
+	if value == "" {
+		v.add(path, RuleRequired, "is required")
+		return false
+	}
+	return true
+}
+
+func (v *validator) document(path, taxID string) {
+	if v.required(path, taxID) && !utils.IsValidTaxID(taxID) {
+		v.add(path, RuleDocument, "must be a valid CPF or CNPJ")
+	}
+}
+
+func (v *validator) url(path, value string) {
+	if value == "" {
+		return
+	}
+	parsed, err := url.Parse(value)
+	if err != nil || (parsed.Scheme != "https" && parsed.Scheme != "http") || parsed.Host == "" {
+		v.add(path, RuleURL, "must be an absolute http or https URL")
+	}
+}
+
+func (v *validator) bankAccount(path string, account *BankAccount) {
+	if account.TaxID != "" {
+		v.document(path+".taxId", account.TaxID)
+	}
+	if v.required(path+".ispb", account.ISPB) && (len(account.ISPB) != 8 || !utils.IsDigits(account.ISPB)) {
+		v.add(path+".ispb", RuleISPB, "must have 8 digits")
+	}
+	if v.required(path+".issuer", account.Issuer) && (len(account.Issuer) > 4 || !utils.IsDigits(account.Issuer)) {
+		v.add(path+".issuer", RuleBranch, "must have up to 4 digits")
+	}
+	if v.required(path+".number", account.Number) && (len(account.Number) > 20 || !utils.IsDigits(account.Number)) {
+		v.add(path+".number", RuleAccount, "must have up to 20 digits")
+	}
+	if v.required(path+".accountType", string(account.AccountType)) && !account.AccountType.IsValid() {
+		v.add(path+".accountType", RuleEnum, "must be one of %s, %s, %s or %s", CheckingAccount, SalaryAccount, SavingsAccount, PrePaidAccount)
+	}
+}
+
+func (t OFAccountsType) IsValid() bool {
+	switch t {
+	case CheckingAccount, SalaryAccount, SavingsAccount, PrePaidAccount:
+		return true
+	default:
+		return false
+	}
+}
+
+// Validate checks the payment for problems the API would reject, so they can
+// be caught before the payment is sent. It returns ValidationErrors listing
+// every invalid field, or nil.
+func (p *PaymentInitiationPayload) Validate() error {
+	v := &validator{}
+
+	switch {
+	case p.Amount <= 0:
+		v.add("amount", RulePositive, "must be greater than zero")
+	case p.Amount != math.Trunc(p.Amount):
+		v.add("amount", RuleCents, "must be a whole number of cents")
+	}
+
+	v.document("user.taxId", p.User.TaxID)
+	if p.BusinessEntity != nil && v.required("businessEntity.taxId", p.BusinessEntity.TaxID) && !utils.IsValidCNPJ(p.BusinessEntity.TaxID) {
+		v.add("businessEntity.taxId", RuleDocument, "must be a valid CNPJ")
+	}
+
+	if p.Debtor != nil {
+		v.bankAccount("debtor", p.Debtor)
+	}
+	if p.Creditor != nil {
+		v.bankAccount("creditor", p.Creditor)
+	}
+	if p.Method != "" {
+		v.method(p)
+	}
+	if err := utils.ValidateTransactionIdentification(p.Method, p.TransactionIdentification); err != nil {
+		v.add("transactionIdentification", RuleFormat, "%v", err)
+	}
+
+	v.url("redirectURL", p.RedirectURL)
+	v.url("redirectOnErrorURL", p.RedirectOnErrorURL)
+
+	if utf8.RuneCountInString(p.Description) > MaxDescriptionLength {
+		v.add("description", RuleMaxLength, "must have at most %d characters", MaxDescriptionLength)
+	}
+
+	today := utils.TodayInBrazil()
+	if p.Schedule != nil {
+		err := p.Schedule.Validate(today)
+		if err != nil {
+			v.add("schedule", RuleSchedule, "%v", err)
+		}
+	}
+	switch {
+	case p.Date.IsZero():
+	case p.Schedule != nil && p.Schedule.Single != nil:
+		if p.Date != p.Schedule.Single.Date {
+			v.add("date", RuleSchedule, "must match schedule.single.date %s", p.Schedule.Single.Date)
+		}
+	case p.Schedule == nil && p.Date.After(today):
+		// A future date schedules the payment like a single schedule.
+		err := validateScheduleDate(p.Date, today, MaxScheduleDays)
+		if err != nil {
+			v.add("date", RuleSchedule, "%v", err)
+		}
+	}
+
+	if len(v.errors) > 0 {
+		return v.errors
+	}
+	return nil
+}
+
+// method checks that the fields required by the payment method are set.
+func (v *validator) method(p *PaymentInitiationPayload) {
+	switch p.Method {
+	case PixManual:
+		// The payer may type the creditor account in the hosted interface.
+	case PixInitiator:
+		if p.Creditor == nil {
+			v.add("creditor", RuleRequired, "is required for %s payments", p.Method)
+		}
+	case PixDict:
+		if p.PixKey == "" {
+			v.add("pixKey", RuleRequired, "is required for %s payments", p.Method)
+		}
+	case PixStaticQRCode, PixDynamicQRCode:
+		if p.QRCode == "" {
+			v.add("qrCode", RuleRequired, "is required for %s payments", p.Method)
+		}
+	default:
+		v.add("method", RuleEnum, "invalid payment method %q", p.Method)
+	}
+}
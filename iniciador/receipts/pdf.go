@@ -0,0 +1,169 @@
+package receipts
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+)
+
+// A4 page size and layout, in points.
+const (
+	pageWidth  = 595
+	pageHeight = 842
+	margin     = 56
+	lineHeight = 16
+	labelWidth = 140
+	valueWidth = pageWidth - 2*margin - labelWidth
+)
+
+// maxValueLines is how many lines a field value may wrap to before it is
+// truncated, so that long descriptions and names cannot overflow the page.
+const maxValueLines = 4
+
+// PDF renders the receipt as a single page PDF document using the standard
+// Helvetica fonts, which need no embedding.
+func (r *Receipt) PDF() ([]byte, error) {
+	var content bytes.Buffer
+	y := pageHeight - margin
+
+	writeText(&content, "F2", 16, margin, y, Title)
+	y -= 2 * lineHeight
+
+	for _, s := range r.sections() {
+		if s.Title != "" {
+			writeText(&content, "F2", 12, margin, y, s.Title)
+			y -= lineHeight
+		}
+		for _, f := range s.Fields {
+			writeText(&content, "F1", 10, margin, y, f.Label)
+			for _, line := range wrapText(f.Value, 10, valueWidth, maxValueLines) {
+				writeText(&content, "F1", 10, margin+labelWidth, y, line)
+				y -= lineHeight
+			}
+		}
+		y -= lineHeight / 2
+	}
+	if y < margin {
+		return nil, fmt.Errorf("receipt does not fit in a single page")
+	}
+
+	objects := []string{
+		"<< /Type /Catalog /Pages 2 0 R >>",
+		"<< /Type /Pages /Kids [3 0 R] /Count 1 >>",
+		fmt.Sprintf("<< /Type /Page /Parent 2 0 R /MediaBox [0 0 %d %d] /Resources << /Font << /F1 5 0 R /F2 6 0 R >> >> /Contents 4 0 R >>", pageWidth, pageHeight),
+		fmt.Sprintf("<< /Length %d >>\nstream\n%sendstream", content.Len(), content.String()),
+		"<< /Type /Font /Subtype /Type1 /BaseFont /Helvetica /Encoding /WinAnsiEncoding >>",
+		"<< /Type /Font /Subtype /Type1 /BaseFont /Helvetica-Bold /Encoding /WinAnsiEncoding >>",
+	}
+
+	var document bytes.Buffer
+	document.WriteString("%PDF-1.4\n")
+	offsets := make([]int, len(objects))
+	for i, object := range objects {
+		offsets[i] = document.Len()
+		fmt.Fprintf(&document, "%d 0 obj\n%s\nendobj\n", i+1, object)
+	}
+
+	xref := document.Len()
+	fmt.Fprintf(&document, "xref\n0 %d\n0000000000 65535 f \n", len(objects)+1)
+	for _, offset := range offsets {
+		fmt.Fprintf(&document, "%010d 00000 n \n", offset)
+	}
+	fmt.Fprintf(&document, "trailer\n<< /Size %d /Root 1 0 R >>\nstartxref\n%d\n%%%%EOF\n", len(objects)+1, xref)
+
+	return document.Bytes(), nil
+}
+
+func writeText(content *bytes.Buffer, font string, size, x, y int, text string) {
+	fmt.Fprintf(content, "BT /%s %d Tf %d %d Td (%s) Tj ET\n", font, size, x, y, pdfString(text))
+}
+
+// pdfString encodes text in WinAnsiEncoding, which covers the Latin-1
+// letters used in Portuguese, escaping the PDF string delimiters.
+// Characters outside it are replaced by "?".
+func pdfString(text string) string {
+	var builder strings.Builder
+	for _, r := range text {
+		switch {
+		case r == '(' || r == ')' || r == '\\':
+			builder.WriteByte('\\')
+			builder.WriteRune(r)
+		case r >= 0x20 && r < 0x7f:
+			builder.WriteRune(r)
+		case r >= 0xa0 && r <= 0xff:
+			fmt.Fprintf(&builder, "\\%03o", r)
+		default:
+			builder.WriteByte('?')
+		}
+	}
+	return builder.String()
+}
+
+// helveticaWidths are the widths of the printable ASCII characters in
+// Helvetica, in thousandths of the font size, from its font metrics.
+var helveticaWidths = [...]int{
+	278, 278, 355, 556, 556, 889, 667, 191, 333, 333, 389, 584, 278, 333, 278, 278, // space to /
+	556, 556, 556, 556, 556, 556, 556, 556, 556, 556, 278, 278, 584, 584, 584, 556, // 0 to ?
+	1015, 667, 667, 722, 722, 667, 611, 778, 722, 278, 500, 667, 556, 833, 722, 778, // @ to O
+	667, 778, 722, 667, 611, 722, 667, 944, 667, 667, 611, 278, 278, 278, 469, 556, // P to _
+	333, 556, 556, 500, 556, 556, 278, 556, 556, 222, 222, 500, 222, 833, 556, 556, // ` to o
+	556, 556, 333, 500, 278, 556, 500, 722, 500, 500, 500, 334, 260, 334, 584, // p to ~
+}
+
+// textWidth measures text in Helvetica of size, in points. Characters
+// outside ASCII, such as accented letters, are measured as wide letters.
+func textWidth(text string, size int) float64 {
+	total := 0
+	for _, r := range text {
+		if r >= ' ' && r <= '~' {
+			total += helveticaWidths[r-' ']
+		} else {
+			total += 722
+		}
+	}
+	return float64(total*size) / 1000
+}
+
+// wrapText splits text into lines that fit width, breaking at spaces, or
+// within words longer than a line. Text beyond maxLines is cut, ending the
+// last line with "...".
+func wrapText(text string, size int, width float64, maxLines int) []string {
+	var lines []string
+	line := ""
+	for _, word := range strings.Fields(text) {
+		candidate := word
+		if line != "" {
+			candidate = line + " " + word
+		}
+		if textWidth(candidate, size) <= width {
+			line = candidate
+			continue
+		}
+		if line != "" {
+			lines = append(lines, line)
+		}
+		for textWidth(word, size) > width {
+			runes := []rune(word)
+			n := len(runes) - 1
+			for n > 1 && textWidth(string(runes[:n]), size) > width {
+				n--
+			}
+			lines = append(lines, string(runes[:n]))
+			word = string(runes[n:])
+		}
+		line = word
+	}
+	if line != "" || len(lines) == 0 {
+		lines = append(lines, line)
+	}
+
+	if len(lines) > maxLines {
+		lines = lines[:maxLines]
+		last := []rune(lines[maxLines-1])
+		for len(last) > 0 && textWidth(string(last)+"...", size) > width {
+			last = last[:len(last)-1]
+		}
+		lines[maxLines-1] = strings.TrimRight(string(last), " ") + "..."
+	}
+	return lines
+}
@@ -0,0 +1,197 @@
+package receipts
+
+import (
+	"bytes"
+	"fmt"
+	"html/template"
+	"strings"
+	"time"
+
+	"iniciador-sdk/iniciador/payments"
+	"iniciador-sdk/iniciador/utils"
+)
+
+const Title = "Comprovante de pagamento Pix"
+
+// Party is the payer or the payee of a receipt. TaxID is already masked.
+type Party struct {
+	Name   string `json:"name,omitempty"`
+	TaxID  string `json:"taxId,omitempty"`
+	PixKey string `json:"pixKey,omitempty"`
+	Bank   string `json:"bank,omitempty"`
+	Branch string `json:"branch,omitempty"`
+	Number string `json:"number,omitempty"`
+}
+
+// Receipt is a Pix receipt (comprovante) of a completed payment. PaidAt is in
+// Brazil time and Amount in cents.
+type Receipt struct {
+	PaymentID                 string    `json:"paymentId"`
+	EndToEndID                string    `json:"endToEndId"`
+	TransactionIdentification string    `json:"transactionIdentification,omitempty"`
+	Amount                    float64   `json:"amount"`
+	PaidAt                    time.Time `json:"paidAt"`
+	Description               string    `json:"description,omitempty"`
+	Payer                     Party     `json:"payer"`
+	Payee                     Party     `json:"payee"`
+	Provider                  string    `json:"provider,omitempty"`
+}
+
+// New builds the receipt of a completed payment. The payer comes from the
+// payment user and debtor account, the payee from the creditor account or
+// the Pix key, and PaidAt from UpdatedAt, when the payment settled, or from
+// CreatedAt when the update time is unknown.
+func New(payment *payments.PaymentInitiationPayload) (*Receipt, error) {
+	if payment.Status == nil || *payment.Status != payments.PaymentCompleted {
+		return nil, fmt.Errorf("payment %s is not %s", payment.ID, payments.PaymentCompleted)
+	}
+	if payment.EndToEndID == "" {
+		return nil, fmt.Errorf("payment %s has no endToEndId", payment.ID)
+	}
+
+	receipt := &Receipt{
+		PaymentID:                 payment.ID,
+		EndToEndID:                payment.EndToEndID,
+		TransactionIdentification: payment.TransactionIdentification,
+		Amount:                    payment.Amount,
+		PaidAt:                    paidAt(payment),
+		Description:               payment.Description,
+		Payer:                     newParty(payment.User.Name, payment.User.TaxID, payment.Debtor),
+		Payee:                     newParty("", "", payment.Creditor),
+	}
+	receipt.Payee.PixKey = payment.PixKey
+	if payment.Provider != nil {
+		receipt.Provider = payment.Provider.TradeName
+	}
+
+	return receipt, nil
+}
+
+func paidAt(payment *payments.PaymentInitiationPayload) time.Time {
+	if !payment.UpdatedAt.IsZero() {
+		return payment.UpdatedAt.InBrazil()
+	}
+	return payment.CreatedAt.InBrazil()
+}
+
+func newParty(name, taxID string, account *payments.BankAccount) Party {
+	party := Party{Name: name}
+	if account != nil {
+		if party.Name == "" {
+			party.Name = account.Name
+		}
+		if taxID == "" {
+			taxID = account.TaxID
+		}
+		party.Branch = account.Issuer
+		party.Number = account.Number
+		switch {
+		case account.Bank != nil && account.Bank.Name != "":
+			party.Bank = account.Bank.Name
+		case account.ISPB != "":
+			party.Bank = "ISPB " + account.ISPB
+		}
+	}
+	if taxID != "" {
+		party.TaxID = utils.MaskTaxID(taxID)
+	}
+	return party
+}
+
+// field is a labeled line of a receipt section.
+type field struct {
+	Label string
+	Value string
+}
+
+type section struct {
+	Title  string
+	Fields []field
+}
+
+// sections lists the content shared by every format, skipping empty fields.
+func (r *Receipt) sections() []section {
+	return []section{
+		{Fields: nonEmpty(
+			field{"Valor", utils.FormatBRL(r.Amount)},
+			field{"Data e hora", r.PaidAt.Format("02/01/2006 15:04:05")},
+			field{"Descrição", r.Description},
+		)},
+		{Title: "Pagador", Fields: r.Payer.fields()},
+		{Title: "Recebedor", Fields: r.Payee.fields()},
+		{Title: "Transação", Fields: nonEmpty(
+			field{"ID da transação", r.EndToEndID},
+			field{"Identificador", r.TransactionIdentification},
+			field{"ID do pagamento", r.PaymentID},
+			field{"Iniciador", r.Provider},
+		)},
+	}
+}
+
+func (p Party) fields() []field {
+	return nonEmpty(
+		field{"Nome", p.Name},
+		field{"CPF/CNPJ", p.TaxID},
+		field{"Chave Pix", p.PixKey},
+		field{"Instituição", p.Bank},
+		field{"Agência", p.Branch},
+		field{"Conta", p.Number},
+	)
+}
+
+func nonEmpty(fields ...field) []field {
+	var result []field
+	for _, f := range fields {
+		if f.Value != "" {
+			result = append(result, f)
+		}
+	}
+	return result
+}
+
+// Text renders the receipt as plain text, e.g. for an email body.
+func (r *Receipt) Text() string {
+	var builder strings.Builder
+	builder.WriteString(Title + "\n")
+	for _, s := range r.sections() {
+		builder.WriteString("\n")
+		if s.Title != "" {
+			builder.WriteString(s.Title + "\n")
+		}
+		for _, f := range s.Fields {
+			builder.WriteString(f.Label + ": " + f.Value + "\n")
+		}
+	}
+	return builder.String()
+}
+
+var htmlTemplate = template.Must(template.New("receipt").Parse(`<!DOCTYPE html>
+<html lang="pt-BR">
+<head>
+<meta charset="utf-8">
+<title>{{.Title}}</title>
+</head>
+<body>
+<h1>{{.Title}}</h1>
+{{range .Sections}}<section>
+{{if .Title}}<h2>{{.Title}}</h2>
+{{end}}<dl>
+{{range .Fields}}<dt>{{.Label}}</dt><dd>{{.Value}}</dd>
+{{end}}</dl>
+</section>
+{{end}}</body>
+</html>
+`))
+
+// HTML renders the receipt as a standalone HTML document.
+func (r *Receipt) HTML() ([]byte, error) {
+	var buffer bytes.Buffer
+	err := htmlTemplate.Execute(&buffer, struct {
+		Title    string
+		Sections []section
+	}{Title, r.sections()})
+	if err != nil {
+		return nil, err
+	}
+	return buffer.Bytes(), nil
+}
@@ -0,0 +1,235 @@
+package reconciliation
+
+import (
+	"time"
+
+	"iniciador-sdk/iniciador/exports"
+	"iniciador-sdk/iniciador/payments"
+	"iniciador-sdk/iniciador/utils"
+)
+
+// Entry is a transaction of a bank statement. Amount is in cents, positive
+// for credits and negative for debits.
+type Entry struct {
+	ID                string    `json:"id,omitempty"`
+	EndToEndID        string    `json:"endToEndId,omitempty"`
+	Amount            float64   `json:"amount"`
+	PostedAt          time.Time `json:"postedAt"`
+	CounterpartyName  string    `json:"counterpartyName,omitempty"`
+	CounterpartyTaxID string    `json:"counterpartyTaxId,omitempty"`
+	Description       string    `json:"description,omitempty"`
+}
+
+// Source provides the entries of a statement. See Entries, CSVSource and
+// OFXSource.
+type Source interface {
+	Entries() ([]Entry, error)
+}
+
+// Entries is a Source of entries already in memory.
+type Entries []Entry
+
+func (e Entries) Entries() ([]Entry, error) {
+	return e, nil
+}
+
+type MatchMethod string
+
+const (
+	ByEndToEndID MatchMethod = "END_TO_END_ID"
+	ByHeuristic  MatchMethod = "HEURISTIC"
+)
+
+type Match struct {
+	Payment *exports.Record `json:"payment"`
+	Entry   Entry           `json:"entry"`
+	Method  MatchMethod     `json:"method"`
+}
+
+// Duplicate is a payment credited more than once: Entries are the extra
+// entries with its EndToEndID, besides the matched one.
+type Duplicate struct {
+	Payment *exports.Record `json:"payment"`
+	Entries []Entry         `json:"entries"`
+}
+
+// Report is the result of a reconciliation. Every completed payment is in
+// exactly one of Matched, AmountMismatched or Missing; every credit entry is
+// in Matched, AmountMismatched, Duplicated or Unexpected.
+type Report struct {
+	Matched []Match `json:"matched"`
+	// AmountMismatched are entries with the EndToEndID of a payment but a
+	// different amount.
+	AmountMismatched []Match           `json:"amountMismatched"`
+	Missing          []*exports.Record `json:"missing"`
+	Duplicated       []Duplicate       `json:"duplicated"`
+	// Unexpected are credit entries that match no payment.
+	Unexpected []Entry `json:"unexpected"`
+	// InvalidEndToEndIDs are the malformed EndToEndIDs of payments and
+	// entries, which usually point to a parsing or integration problem.
+	InvalidEndToEndIDs []string `json:"invalidEndToEndIds,omitempty"`
+}
+
+// IsReconciled reports whether every payment was found exactly once, with no
+// unexpected entries or malformed EndToEndIDs.
+func (r *Report) IsReconciled() bool {
+	return len(r.InvalidEndToEndIDs) == 0 && len(r.AmountMismatched) == 0 && len(r.Missing) == 0 && len(r.Duplicated) == 0 && len(r.Unexpected) == 0
+}
+
+type Options struct {
+	// DateTolerance is how far apart, in days, the Brazil date of an entry
+	// and of a payment can be for a heuristic match. Defaults to 1.
+	DateTolerance int
+	// CreditorTaxID restricts the reconciliation to payments to this CPF or
+	// CNPJ, the holder of the statement account. Payments without a payee
+	// tax ID are kept.
+	CreditorTaxID string
+	// DisableHeuristics only matches entries by EndToEndID.
+	DisableHeuristics bool
+}
+
+// Reconcile matches the credit entries of source to the completed payments
+// among records, first by EndToEndID and then, for the entries and payments
+// left, by equal amount, close dates and, when the entry has it, the
+// counterparty tax ID. Records with other statuses and debit entries are
+// ignored.
+func Reconcile(records []*exports.Record, source Source, options *Options) (*Report, error) {
+	if options == nil {
+		options = &Options{}
+	}
+	dateTolerance := options.DateTolerance
+	if dateTolerance == 0 {
+		dateTolerance = 1
+	}
+
+	entries, err := source.Entries()
+	if err != nil {
+		return nil, err
+	}
+
+	var expected []*exports.Record
+	for _, record := range records {
+		if record.Status != string(payments.PaymentCompleted) {
+			continue
+		}
+		if options.CreditorTaxID != "" && record.PayeeTaxID != "" && utils.OnlyDigits(record.PayeeTaxID) != utils.OnlyDigits(options.CreditorTaxID) {
+			continue
+		}
+		expected = append(expected, record)
+	}
+
+	report := &Report{}
+	for _, record := range expected {
+		report.checkEndToEndID(record.EndToEndID)
+	}
+	for _, entry := range entries {
+		report.checkEndToEndID(entry.EndToEndID)
+	}
+
+	isMatched := make([]bool, len(expected))
+	byEndToEndID := make(map[string]int, len(expected))
+	for i, record := range expected {
+		if record.EndToEndID != "" {
+			byEndToEndID[record.EndToEndID] = i
+		}
+	}
+
+	// Match by EndToEndID, keeping the entries left for the heuristics
+	var remaining []Entry
+	duplicates := make(map[int]int)
+	for _, entry := range entries {
+		if utils.AmountCents(entry.Amount) <= 0 {
+			continue
+		}
+		i, ok := byEndToEndID[entry.EndToEndID]
+		if entry.EndToEndID == "" || !ok {
+			remaining = append(remaining, entry)
+			continue
+		}
+
+		record := expected[i]
+		switch {
+		case isMatched[i]:
+			index, ok := duplicates[i]
+			if !ok {
+				index = len(report.Duplicated)
+				duplicates[i] = index
+				report.Duplicated = append(report.Duplicated, Duplicate{Payment: record})
+			}
+			report.Duplicated[index].Entries = append(report.Duplicated[index].Entries, entry)
+		case utils.AmountCents(entry.Amount) != utils.AmountCents(record.Amount):
+			isMatched[i] = true
+			report.AmountMismatched = append(report.AmountMismatched, Match{Payment: record, Entry: entry, Method: ByEndToEndID})
+		default:
+			isMatched[i] = true
+			report.Matched = append(report.Matched, Match{Payment: record, Entry: entry, Method: ByEndToEndID})
+		}
+	}
+
+	// Match the rest by amount, date and counterparty, closest date first
+	for _, entry := range remaining {
+		best := -1
+		bestDistance := 0
+		if !options.DisableHeuristics {
+			for i, record := range expected {
+				if isMatched[i] || !isCandidate(record, &entry) {
+					continue
+				}
+				distance := dateDistance(record, &entry)
+				if distance <= dateTolerance && (best < 0 || distance < bestDistance) {
+					best, bestDistance = i, distance
+				}
+			}
+		}
+		if best < 0 {
+			report.Unexpected = append(report.Unexpected, entry)
+			continue
+		}
+		isMatched[best] = true
+		report.Matched = append(report.Matched, Match{Payment: expected[best], Entry: entry, Method: ByHeuristic})
+	}
+
+	for i, record := range expected {
+		if !isMatched[i] {
+			report.Missing = append(report.Missing, record)
+		}
+	}
+
+	return report, nil
+}
+
+func (r *Report) checkEndToEndID(id string) {
+	if id != "" && !utils.IsValidEndToEndID(id) {
+		r.InvalidEndToEndIDs = append(r.InvalidEndToEndIDs, id)
+	}
+}
+
+// isCandidate reports whether entry could be the credit of record. Entries
+// carrying an EndToEndID only match records without one, and the
+// counterparty, when known, must be the payer.
+func isCandidate(record *exports.Record, entry *Entry) bool {
+	if utils.AmountCents(record.Amount) != utils.AmountCents(entry.Amount) {
+		return false
+	}
+	if entry.EndToEndID != "" && record.EndToEndID != "" {
+		return false
+	}
+	if entry.CounterpartyTaxID != "" && record.PayerTaxID != "" {
+		return utils.OnlyDigits(entry.CounterpartyTaxID) == utils.OnlyDigits(record.PayerTaxID)
+	}
+	return true
+}
+
+// dateDistance is the number of days between the Brazil dates of the entry
+// and of the payment settlement.
+func dateDistance(record *exports.Record, entry *Entry) int {
+	paidAt := record.UpdatedAt
+	if paidAt.IsZero() {
+		paidAt = record.CreatedAt
+	}
+	distance := utils.DateOf(entry.PostedAt.In(utils.BrazilLocation)).DaysSince(utils.DateOf(paidAt.In(utils.BrazilLocation)))
+	if distance < 0 {
+		return -distance
+	}
+	return distance
+}
@@ -0,0 +1,243 @@
+package reconciliation
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"iniciador-sdk/iniciador/utils"
+)
+
+// endToEndIDPattern finds Pix EndToEndID candidates in free text, such as
+// the memo of a statement entry.
+var endToEndIDPattern = regexp.MustCompile(`E[0-9]{8}[0-9]{12}[A-Za-z0-9]{11}`)
+
+// findEndToEndID returns the first valid EndToEndID in text, or "".
+func findEndToEndID(text string) string {
+	for _, candidate := range endToEndIDPattern.FindAllString(text, -1) {
+		if utils.IsValidEndToEndID(candidate) {
+			return candidate
+		}
+	}
+	return ""
+}
+
+// CSVSource reads entries from a CSV file with a header row. The column
+// fields name the header of each entry field; only AmountColumn is required.
+// Amounts are decimal reais, with either a point or a comma separator.
+type CSVSource struct {
+	Reader io.Reader
+	Comma  rune
+
+	IDColumn                string
+	EndToEndIDColumn        string
+	AmountColumn            string
+	PostedAtColumn          string
+	CounterpartyNameColumn  string
+	CounterpartyTaxIDColumn string
+	DescriptionColumn       string
+
+	// TimeLayouts are tried in order to parse PostedAtColumn. Values
+	// without an offset are read as Brazil time.
+	TimeLayouts []string
+}
+
+// NewCSVSource creates a CSVSource with the column names written by
+// exports, e.g. "endToEndId" and "amount", and "postedAt" for the date.
+func NewCSVSource(reader io.Reader) *CSVSource {
+	return &CSVSource{
+		Reader:                  reader,
+		Comma:                   ',',
+		IDColumn:                "id",
+		EndToEndIDColumn:        "endToEndId",
+		AmountColumn:            "amount",
+		PostedAtColumn:          "postedAt",
+		CounterpartyNameColumn:  "counterpartyName",
+		CounterpartyTaxIDColumn: "counterpartyTaxId",
+		DescriptionColumn:       "description",
+		TimeLayouts:             []string{time.RFC3339, "2006-01-02 15:04:05", "2006-01-02", "02/01/2006 15:04:05", "02/01/2006"},
+	}
+}
+
+func (c *CSVSource) Entries() ([]Entry, error) {
+	reader := csv.NewReader(c.Reader)
+	if c.Comma != 0 {
+		reader.Comma = c.Comma
+	}
+	reader.FieldsPerRecord = -1
+
+	header, err := reader.Read()
+	if err != nil {
+		return nil, fmt.Errorf("failed to read the CSV header: %v", err)
+	}
+	columns := make(map[string]int, len(header))
+	for i, name := range header {
+		columns[strings.TrimSpace(name)] = i
+	}
+	if _, ok := columns[c.AmountColumn]; !ok {
+		return nil, fmt.Errorf("missing amount column %q", c.AmountColumn)
+	}
+
+	var entries []Entry
+	for line := 2; ; line++ {
+		row, err := reader.Read()
+		if err == io.EOF {
+			return entries, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+		value := func(column string) string {
+			i, ok := columns[column]
+			if column == "" || !ok || i >= len(row) {
+				return ""
+			}
+			return strings.TrimSpace(row[i])
+		}
+
+		amount, err := parseAmount(value(c.AmountColumn))
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %v", line, err)
+		}
+		entry := Entry{
+			ID:                value(c.IDColumn),
+			EndToEndID:        value(c.EndToEndIDColumn),
+			Amount:            amount,
+			CounterpartyName:  value(c.CounterpartyNameColumn),
+			CounterpartyTaxID: value(c.CounterpartyTaxIDColumn),
+			Description:       value(c.DescriptionColumn),
+		}
+		if entry.EndToEndID == "" {
+			entry.EndToEndID = findEndToEndID(entry.Description)
+		}
+		if postedAt := value(c.PostedAtColumn); postedAt != "" {
+			entry.PostedAt, err = c.parseTime(postedAt)
+			if err != nil {
+				return nil, fmt.Errorf("line %d: %v", line, err)
+			}
+		}
+		entries = append(entries, entry)
+	}
+}
+
+func (c *CSVSource) parseTime(value string) (time.Time, error) {
+	for _, layout := range c.TimeLayouts {
+		parsed, err := time.ParseInLocation(layout, value, utils.BrazilLocation)
+		if err == nil {
+			return parsed, nil
+		}
+	}
+	return time.Time{}, fmt.Errorf("invalid date %q", value)
+}
+
+// OFXSource reads the transactions of an OFX statement, in either the SGML
+// (1.x) or XML (2.x) flavor. The EndToEndID is taken from the FITID, NAME or
+// MEMO of the transaction, where banks usually put it.
+type OFXSource struct {
+	Reader io.Reader
+}
+
+var (
+	ofxTransactionPattern = regexp.MustCompile(`(?is)<STMTTRN>(.*?)</STMTTRN>`)
+	ofxFieldPattern       = regexp.MustCompile(`(?i)<([A-Z0-9.]+)>([^<\r\n]*)`)
+	ofxTimePattern        = regexp.MustCompile(`^(\d{8})(\d{6})?(?:\.\d+)?(?:\[([+-]?\d+(?:\.\d+)?)(?::[^\]]*)?\])?$`)
+)
+
+func (o *OFXSource) Entries() ([]Entry, error) {
+	data, err := ioutil.ReadAll(o.Reader)
+	if err != nil {
+		return nil, err
+	}
+
+	var entries []Entry
+	for _, transaction := range ofxTransactionPattern.FindAllSubmatch(data, -1) {
+		fields := make(map[string]string)
+		for _, field := range ofxFieldPattern.FindAllSubmatch(transaction[1], -1) {
+			fields[strings.ToUpper(string(field[1]))] = unescapeOFX(strings.TrimSpace(string(field[2])))
+		}
+
+		amount, err := parseAmount(fields["TRNAMT"])
+		if err != nil {
+			return nil, fmt.Errorf("transaction %s: %v", fields["FITID"], err)
+		}
+		postedAt, err := parseOFXTime(fields["DTPOSTED"])
+		if err != nil {
+			return nil, fmt.Errorf("transaction %s: %v", fields["FITID"], err)
+		}
+
+		entries = append(entries, Entry{
+			ID:               fields["FITID"],
+			EndToEndID:       findEndToEndID(fields["FITID"] + " " + fields["NAME"] + " " + fields["MEMO"]),
+			Amount:           amount,
+			PostedAt:         postedAt,
+			CounterpartyName: fields["NAME"],
+			Description:      fields["MEMO"],
+		})
+	}
+	return entries, nil
+}
+
+// parseOFXTime parses dates such as "20240229233000[-3:BRT]". Dates
+// without an offset are read as Brazil time.
+func parseOFXTime(value string) (time.Time, error) {
+	match := ofxTimePattern.FindStringSubmatch(value)
+	if match == nil {
+		return time.Time{}, fmt.Errorf("invalid date %q", value)
+	}
+
+	clock := match[2]
+	if clock == "" {
+		clock = "000000"
+	}
+	location := utils.BrazilLocation
+	if match[3] != "" {
+		hours, err := strconv.ParseFloat(match[3], 64)
+		if err != nil {
+			return time.Time{}, fmt.Errorf("invalid date %q", value)
+		}
+		location = time.FixedZone("", int(hours*3600))
+	}
+	return time.ParseInLocation("20060102150405", match[1]+clock, location)
+}
+
+func unescapeOFX(value string) string {
+	return strings.NewReplacer("&lt;", "<", "&gt;", ">", "&quot;", `"`, "&apos;", "'", "&amp;", "&").Replace(value)
+}
+
+// parseAmount converts a decimal amount in reais, such as "-1234.56",
+// "1.234,56" or "R$ 10", to cents without float rounding.
+func parseAmount(value string) (float64, error) {
+	cleaned := strings.TrimSpace(strings.TrimPrefix(strings.TrimSpace(value), "R$"))
+	negative := strings.HasPrefix(cleaned, "-")
+	cleaned = strings.TrimLeft(cleaned, "+-")
+
+	// The last separator is the decimal one when followed by up to 2 digits
+	units, fraction := cleaned, ""
+	if i := strings.LastIndexAny(cleaned, ".,"); i >= 0 && len(cleaned)-i-1 <= 2 {
+		units, fraction = cleaned[:i], cleaned[i+1:]
+	}
+	units = strings.NewReplacer(".", "", ",", "").Replace(units)
+	for len(fraction) < 2 {
+		fraction += "0"
+	}
+	if units == "" {
+		units = "0"
+	}
+	if !utils.IsDigits(units) || !utils.IsDigits(fraction) {
+		return 0, fmt.Errorf("invalid amount %q", value)
+	}
+
+	cents, err := strconv.ParseInt(units+fraction, 10, 64)
+	if err != nil {
+		return 0, fmt.Errorf("invalid amount %q", value)
+	}
+	if negative {
+		cents = -cents
+	}
+	return float64(cents), nil
+}
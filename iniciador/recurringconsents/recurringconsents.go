@@ -0,0 +1,260 @@
+package recurringconsents
+
+import (
+	"fmt"
+	"math"
+	"net/http"
+	"net/url"
+
+	"iniciador-sdk/iniciador/auth"
+	"iniciador-sdk/iniciador/payments"
+	"iniciador-sdk/iniciador/utils"
+)
+
+type ConsentStatus string
+
+const (
+	AwaitingAuthorization ConsentStatus = "AWAITING_AUTHORIZATION"
+	Authorized            ConsentStatus = "AUTHORIZED"
+	Rejected              ConsentStatus = "REJECTED"
+	Revoked               ConsentStatus = "REVOKED"
+	Expired               ConsentStatus = "EXPIRED"
+)
+
+type Periodicity string
+
+const (
+	Weekly     Periodicity = "SEMANAL"
+	Monthly    Periodicity = "MENSAL"
+	Quarterly  Periodicity = "TRIMESTRAL"
+	SemiAnnual Periodicity = "SEMESTRAL"
+	Annual     Periodicity = "ANUAL"
+)
+
+// periodDays is the minimum number of days in each period, used to check that
+// a consent lasts for at least one charge cycle.
+var periodDays = map[Periodicity]int{
+	Weekly:     7,
+	Monthly:    28,
+	Quarterly:  89,
+	SemiAnnual: 181,
+	Annual:     365,
+}
+
+type RejectionReason struct {
+	Code   string `json:"code"`
+	Detail string `json:"detail"`
+}
+
+// RecurringConsentPayload is a Pix Automático authorization. A consent either
+// charges a FixedAmount every period or a variable amount limited by the
+// optional MaxAmount. Amounts are in cents.
+type RecurringConsentPayload struct {
+	utils.UnknownFields `json:"-"`
+
+	ID                 string                `json:"id"`
+	CreatedAt          utils.Timestamp       `json:"createdAt"`
+	Status             ConsentStatus         `json:"status,omitempty"`
+	ExternalID         string                `json:"externalId,omitempty"`
+	ParticipantID      string                `json:"participantId,omitempty"`
+	User               payments.User         `json:"user"`
+	BusinessEntity     *payments.User        `json:"businessEntity,omitempty"`
+	Debtor             *payments.BankAccount `json:"debtor,omitempty"`
+	Creditor           *payments.BankAccount `json:"creditor,omitempty"`
+	FixedAmount        float64               `json:"fixedAmount,omitempty"`
+	MaxAmount          float64               `json:"maxAmount,omitempty"`
+	Periodicity        Periodicity           `json:"periodicity"`
+	StartDate          utils.Date            `json:"startDate"`
+	ExpirationDate     utils.Date            `json:"expirationDate,omitempty"`
+	Description        string                `json:"description,omitempty"`
+	RedirectURL        string                `json:"redirectURL,omitempty"`
+	RedirectOnErrorURL string                `json:"redirectOnErrorURL,omitempty"`
+	RedirectConsentURL string                `json:"redirectConsentURL,omitempty"`
+	RejectionReason    *RejectionReason      `json:"rejectionReason,omitempty"`
+	Metadata           payments.Metadata     `json:"metadata,omitempty"`
+}
+
+// ChargeInput is a single charge initiated under an authorized consent,
+// without redirecting the user.
+type ChargeInput struct {
+	Amount      float64    `json:"amount"`
+	Date        utils.Date `json:"date,omitempty"`
+	Description string     `json:"description,omitempty"`
+	ExternalID  string     `json:"externalId,omitempty"`
+}
+
+type Cursor struct {
+	AfterCursor  string `json:"afterCursor"`
+	BeforeCursor string `json:"beforeCursor"`
+}
+
+type RecurringConsentsFilter struct {
+	ExternalID   string
+	Status       ConsentStatus
+	Limit        string
+	AfterCursor  string
+	BeforeCursor string
+}
+
+type RecurringConsentsOutput struct {
+	Data   []RecurringConsentPayload `json:"data"`
+	Cursor Cursor                    `json:"cursor"`
+}
+
+type revokeRequest struct {
+	Status           ConsentStatus `json:"status"`
+	RevocationReason string        `json:"revocationReason,omitempty"`
+}
+
+// Validate checks the amount and periodicity rules of a new consent against
+// today, the current civil date in Brazil.
+func (c *RecurringConsentPayload) Validate(today utils.Date) error {
+	if c.FixedAmount != 0 && c.MaxAmount != 0 {
+		return fmt.Errorf("fixedAmount and maxAmount cannot be used together")
+	}
+	if err := validateAmount("fixedAmount", c.FixedAmount, true); err != nil {
+		return err
+	}
+	if err := validateAmount("maxAmount", c.MaxAmount, true); err != nil {
+		return err
+	}
+
+	days, ok := periodDays[c.Periodicity]
+	if !ok {
+		return fmt.Errorf("invalid periodicity %q", c.Periodicity)
+	}
+	if c.StartDate.IsZero() {
+		return fmt.Errorf("startDate is required")
+	}
+	if !c.StartDate.After(today) {
+		return fmt.Errorf("startDate %s must be after %s", c.StartDate, today)
+	}
+	if !c.ExpirationDate.IsZero() && c.ExpirationDate.DaysSince(c.StartDate) < days {
+		return fmt.Errorf("expirationDate %s must allow at least one %s charge after %s", c.ExpirationDate, c.Periodicity, c.StartDate)
+	}
+
+	return nil
+}
+
+// ValidateCharge checks that charge can be initiated under the consent.
+func (c *RecurringConsentPayload) ValidateCharge(charge *ChargeInput) error {
+	if c.Status != Authorized {
+		return fmt.Errorf("consent %s is %s, charges require an %s consent", c.ID, c.Status, Authorized)
+	}
+	if err := validateAmount("amount", charge.Amount, false); err != nil {
+		return err
+	}
+	if c.FixedAmount != 0 && charge.Amount != c.FixedAmount {
+		return fmt.Errorf("amount %.0f must be equal to the consent fixed amount %.0f", charge.Amount, c.FixedAmount)
+	}
+	if c.MaxAmount != 0 && charge.Amount > c.MaxAmount {
+		return fmt.Errorf("amount %.0f exceeds the consent max amount %.0f", charge.Amount, c.MaxAmount)
+	}
+	if !charge.Date.IsZero() {
+		if charge.Date.Before(c.StartDate) {
+			return fmt.Errorf("charge date %s is before the consent start date %s", charge.Date, c.StartDate)
+		}
+		if !c.ExpirationDate.IsZero() && charge.Date.After(c.ExpirationDate) {
+			return fmt.Errorf("charge date %s is after the consent expiration date %s", charge.Date, c.ExpirationDate)
+		}
+	}
+
+	return nil
+}
+
+func validateAmount(field string, amount float64, optional bool) error {
+	if optional && amount == 0 {
+		return nil
+	}
+	if amount <= 0 || amount != math.Trunc(amount) {
+		return fmt.Errorf("%s must be a positive amount in cents, got %v", field, amount)
+	}
+	return nil
+}
+
+func Create(accessToken string, consent *RecurringConsentPayload, authClient *auth.AuthClient) (*RecurringConsentPayload, error) {
+	err := consent.Validate(utils.TodayInBrazil())
+	if err != nil {
+		return nil, err
+	}
+
+	var output RecurringConsentPayload
+	url := fmt.Sprintf("%s/recurring-consents", authClient.Environment)
+	err = utils.DoRequest(http.MethodPost, url, accessToken, consent, &output)
+	if err != nil {
+		return nil, err
+	}
+
+	return &output, nil
+}
+
+func Get(accessToken, consentID string, authClient *auth.AuthClient) (*RecurringConsentPayload, error) {
+	var output RecurringConsentPayload
+	url := fmt.Sprintf("%s/recurring-consents/%s", authClient.Environment, consentID)
+	err := utils.DoRequest(http.MethodGet, url, accessToken, nil, &output)
+	if err != nil {
+		return nil, err
+	}
+
+	return &output, nil
+}
+
+func Revoke(accessToken, consentID, reason string, authClient *auth.AuthClient) (*RecurringConsentPayload, error) {
+	var output RecurringConsentPayload
+	url := fmt.Sprintf("%s/recurring-consents/%s", authClient.Environment, consentID)
+	err := utils.DoRequest(http.MethodPatch, url, accessToken, &revokeRequest{Status: Revoked, RevocationReason: reason}, &output)
+	if err != nil {
+		return nil, err
+	}
+
+	return &output, nil
+}
+
+func List(accessToken string, filters *RecurringConsentsFilter, authClient *auth.AuthClient) (*RecurringConsentsOutput, error) {
+	filterParams := make(url.Values)
+
+	if filters != nil {
+		if filters.ExternalID != "" {
+			filterParams.Set("externalId", filters.ExternalID)
+		}
+		if filters.Status != "" {
+			filterParams.Set("status", string(filters.Status))
+		}
+		if filters.Limit != "" {
+			filterParams.Set("limit", filters.Limit)
+		}
+		if filters.AfterCursor != "" {
+			filterParams.Set("afterCursor", filters.AfterCursor)
+		}
+		if filters.BeforeCursor != "" {
+			filterParams.Set("beforeCursor", filters.BeforeCursor)
+		}
+	}
+
+	var output RecurringConsentsOutput
+	url := fmt.Sprintf("%s/recurring-consents?%s", authClient.Environment, filterParams.Encode())
+	err := utils.DoRequest(http.MethodGet, url, accessToken, nil, &output)
+	if err != nil {
+		return nil, err
+	}
+
+	return &output, nil
+}
+
+// Charge initiates a payment under an authorized consent. The charge is
+// validated against the consent's status, amount and dates before sending.
+func Charge(accessToken string, consent *RecurringConsentPayload, charge *ChargeInput, authClient *auth.AuthClient) (*payments.PaymentInitiationPayload, error) {
+	err := consent.ValidateCharge(charge)
+	if err != nil {
+		return nil, err
+	}
+
+	var output payments.PaymentInitiationPayload
+	url := fmt.Sprintf("%s/recurring-consents/%s/payments", authClient.Environment, consent.ID)
+	err = utils.DoRequest(http.MethodPost, url, accessToken, charge, &output)
+	if err != nil {
+		return nil, err
+	}
+
+	return &output, nil
+}
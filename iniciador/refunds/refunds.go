@@ -0,0 +1,151 @@
+package refunds
+
+import (
+	"fmt"
+	"math"
+	"net/http"
+
+	"iniciador-sdk/iniciador/auth"
+	"iniciador-sdk/iniciador/payments"
+	"iniciador-sdk/iniciador/utils"
+)
+
+type RefundStatus string
+
+const (
+	Pending   RefundStatus = "REFUND_PENDING"
+	Completed RefundStatus = "REFUND_COMPLETED"
+	Rejected  RefundStatus = "REFUND_REJECTED"
+	Err       RefundStatus = "ERROR"
+)
+
+// RefundReason is the Pix devolução reason code.
+type RefundReason string
+
+const (
+	BankError        RefundReason = "BE08"
+	Fraud            RefundReason = "FR01"
+	CustomerRequest  RefundReason = "MD06"
+	WithdrawalOrFees RefundReason = "SL02"
+)
+
+type RefundPayload struct {
+	utils.UnknownFields `json:"-"`
+
+	ID          string          `json:"id"`
+	CreatedAt   utils.Timestamp `json:"createdAt"`
+	UpdatedAt   utils.Timestamp `json:"updatedAt,omitempty"`
+	PaymentID   string          `json:"paymentId"`
+	EndToEndID  string          `json:"endToEndId"`
+	ReturnID    string          `json:"returnId,omitempty"`
+	ExternalID  string          `json:"externalId,omitempty"`
+	Amount      float64         `json:"amount"`
+	Status      RefundStatus    `json:"status"`
+	Reason      RefundReason    `json:"reason,omitempty"`
+	Description string          `json:"description,omitempty"`
+	Error       *payments.Error `json:"error,omitempty"`
+}
+
+// RefundInput describes a refund of a completed payment. A zero Amount
+// refunds everything that has not been refunded yet.
+type RefundInput struct {
+	Amount      float64
+	Reason      RefundReason
+	Description string
+	ExternalID  string
+}
+
+type RefundsOutput struct {
+	Data []RefundPayload `json:"data"`
+}
+
+type refundRequest struct {
+	EndToEndID  string       `json:"endToEndId"`
+	Amount      float64      `json:"amount"`
+	Reason      RefundReason `json:"reason,omitempty"`
+	Description string       `json:"description,omitempty"`
+	ExternalID  string       `json:"externalId,omitempty"`
+}
+
+// Refundable returns how much of payment can still be refunded, given the
+// refunds already made against it. Rejected and failed refunds are ignored.
+func Refundable(payment *payments.PaymentInitiationPayload, refunds []RefundPayload) float64 {
+	refunded := 0.0
+	for _, refund := range refunds {
+		if refund.Status == Rejected || refund.Status == Err {
+			continue
+		}
+		refunded += refund.Amount
+	}
+	return math.Max(payment.Amount-refunded, 0)
+}
+
+// Create refunds a completed payment using its EndToEndID. Prior refunds are
+// listed first so the total refunded never exceeds the payment amount.
+func Create(accessToken string, payment *payments.PaymentInitiationPayload, input *RefundInput, authClient *auth.AuthClient) (*RefundPayload, error) {
+	if payment.Status == nil || *payment.Status != payments.PaymentCompleted {
+		return nil, fmt.Errorf("only %s payments can be refunded", payments.PaymentCompleted)
+	}
+	if payment.EndToEndID == "" {
+		return nil, fmt.Errorf("payment %s has no endToEndId to refund", payment.ID)
+	}
+	if input.Amount < 0 || input.Amount != math.Trunc(input.Amount) {
+		return nil, fmt.Errorf("refund amount must be a positive amount in cents, got %v", input.Amount)
+	}
+
+	previous, err := List(accessToken, payment.ID, authClient)
+	if err != nil {
+		return nil, err
+	}
+
+	refundable := Refundable(payment, previous.Data)
+	if refundable == 0 {
+		return nil, fmt.Errorf("payment %s has already been fully refunded", payment.ID)
+	}
+	amount := input.Amount
+	if amount == 0 {
+		amount = refundable
+	}
+	if amount > refundable {
+		return nil, fmt.Errorf("refund amount %.0f exceeds the refundable amount %.0f of payment %s", amount, refundable, payment.ID)
+	}
+
+	request := &refundRequest{
+		EndToEndID:  payment.EndToEndID,
+		Amount:      amount,
+		Reason:      input.Reason,
+		Description: input.Description,
+		ExternalID:  input.ExternalID,
+	}
+
+	var output RefundPayload
+	url := fmt.Sprintf("%s/payments/%s/refunds", authClient.Environment, payment.ID)
+	err = utils.DoRequest(http.MethodPost, url, accessToken, request, &output)
+	if err != nil {
+		return nil, err
+	}
+
+	return &output, nil
+}
+
+func Get(accessToken, paymentID, refundID string, authClient *auth.AuthClient) (*RefundPayload, error) {
+	var output RefundPayload
+	url := fmt.Sprintf("%s/payments/%s/refunds/%s", authClient.Environment, paymentID, refundID)
+	err := utils.DoRequest(http.MethodGet, url, accessToken, nil, &output)
+	if err != nil {
+		return nil, err
+	}
+
+	return &output, nil
+}
+
+func List(accessToken, paymentID string, authClient *auth.AuthClient) (*RefundsOutput, error) {
+	var output RefundsOutput
+	url := fmt.Sprintf("%s/payments/%s/refunds", authClient.Environment, paymentID)
+	err := utils.DoRequest(http.MethodGet, url, accessToken, nil, &output)
+	if err != nil {
+		return nil, err
+	}
+
+	return &output, nil
+}
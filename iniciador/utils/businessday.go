@@ -0,0 +1,79 @@
+package utils
+
+import "time"
+
+var fixedHolidays = []struct {
+	month time.Month
+	day   int
+}{
+	{time.January, 1},   // Confraternização Universal
+	{time.April, 21},    // Tiradentes
+	{time.May, 1},       // Dia do Trabalho
+	{time.September, 7}, // Independência
+	{time.October, 12},  // Nossa Senhora Aparecida
+	{time.November, 2},  // Finados
+	{time.November, 15}, // Proclamação da República
+	{time.November, 20}, // Consciência Negra, national since 2024
+	{time.December, 25}, // Natal
+}
+
+// easter returns Easter Sunday of year, using the anonymous Gregorian algorithm.
+func easter(year int) Date {
+	a := year % 19
+	b := year / 100
+	c := year % 100
+	d := b / 4
+	e := b % 4
+	f := (b + 8) / 25
+	g := (b - f + 1) / 3
+	h := (19*a + b - d - g + 15) % 30
+	i := c / 4
+	k := c % 4
+	l := (32 + 2*e + 2*i - h - k) % 7
+	m := (a + 11*h + 22*l) / 451
+	month := (h + l - 7*m + 114) / 31
+	day := (h+l-7*m+114)%31 + 1
+	return NewDate(year, time.Month(month), day)
+}
+
+// IsBankHoliday reports whether d is a national bank holiday in Brazil,
+// including Carnival, Good Friday and Corpus Christi.
+func IsBankHoliday(d Date) bool {
+	for _, holiday := range fixedHolidays {
+		if d.Month == holiday.month && d.Day == holiday.day {
+			if holiday.month == time.November && holiday.day == 20 && d.Year < 2024 {
+				continue
+			}
+			return true
+		}
+	}
+
+	easterSunday := easter(d.Year)
+	for _, offset := range []int{-48, -47, -2, 60} {
+		if d == easterSunday.AddDays(offset) {
+			return true
+		}
+	}
+	return false
+}
+
+// IsBusinessDay reports whether d is a weekday that is not a bank holiday.
+func IsBusinessDay(d Date) bool {
+	weekday := d.Weekday()
+	return weekday != time.Saturday && weekday != time.Sunday && !IsBankHoliday(d)
+}
+
+// NextBusinessDay returns d if it is a business day, or the first business
+// day after it.
+func NextBusinessDay(d Date) Date {
+	for !IsBusinessDay(d) {
+		d = d.AddDays(1)
+	}
+	return d
+}
+
+// BusinessDate returns the Brazilian business date of t: its civil date in
+// America/Sao_Paulo, moved forward to the next business day when needed.
+func BusinessDate(t time.Time) Date {
+	return NextBusinessDay(DateOf(t.In(BrazilLocation)))
+}
@@ -0,0 +1,131 @@
+package utils
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+)
+
+const dateLayout = "2006-01-02"
+
+// BrazilLocation is the America/Sao_Paulo timezone used by the Pix arrangement
+// to decide calendar dates. When the timezone database is not available it
+// falls back to a fixed UTC-03:00 offset, which Brazil has kept since 2019.
+var BrazilLocation = loadBrazilLocation()
+
+func loadBrazilLocation() *time.Location {
+	location, err := time.LoadLocation("America/Sao_Paulo")
+	if err != nil {
+		return time.FixedZone("BRT", -3*60*60)
+	}
+	return location
+}
+
+// Date is a civil date (year, month and day) without time or timezone,
+// serialized as "YYYY-MM-DD".
+type Date struct {
+	Year  int
+	Month time.Month
+	Day   int
+}
+
+func NewDate(year int, month time.Month, day int) Date {
+	return DateOf(time.Date(year, month, day, 0, 0, 0, 0, time.UTC))
+}
+
+// DateOf returns the civil date of t in t's own location.
+func DateOf(t time.Time) Date {
+	year, month, day := t.Date()
+	return Date{Year: year, Month: month, Day: day}
+}
+
+// TodayInBrazil returns the current civil date in America/Sao_Paulo.
+func TodayInBrazil() Date {
+	return DateOf(time.Now().In(BrazilLocation))
+}
+
+func ParseDate(value string) (Date, error) {
+	t, err := time.Parse(dateLayout, value)
+	if err != nil {
+		return Date{}, fmt.Errorf("invalid date %q, expected YYYY-MM-DD", value)
+	}
+	return DateOf(t), nil
+}
+
+func (d Date) String() string {
+	if d.IsZero() {
+		return ""
+	}
+	return fmt.Sprintf("%04d-%02d-%02d", d.Year, d.Month, d.Day)
+}
+
+func (d Date) IsZero() bool {
+	return d.Year == 0 && d.Month == 0 && d.Day == 0
+}
+
+// In returns the time at the start of the date in the given location.
+func (d Date) In(location *time.Location) time.Time {
+	return time.Date(d.Year, d.Month, d.Day, 0, 0, 0, 0, location)
+}
+
+func (d Date) AddDays(days int) Date {
+	return DateOf(d.In(time.UTC).AddDate(0, 0, days))
+}
+
+func (d Date) AddMonths(months int) Date {
+	return DateOf(d.In(time.UTC).AddDate(0, months, 0))
+}
+
+func (d Date) Weekday() time.Weekday {
+	return d.In(time.UTC).Weekday()
+}
+
+// DaysSince returns the number of days from other to d.
+func (d Date) DaysSince(other Date) int {
+	return int(d.In(time.UTC).Sub(other.In(time.UTC)).Hours() / 24)
+}
+
+func (d Date) Before(other Date) bool {
+	return d.In(time.UTC).Before(other.In(time.UTC))
+}
+
+func (d Date) After(other Date) bool {
+	return d.In(time.UTC).After(other.In(time.UTC))
+}
+
+func (d Date) MarshalJSON() ([]byte, error) {
+	if d.IsZero() {
+		return []byte("null"), nil
+	}
+	return json.Marshal(d.String())
+}
+
+func (d *Date) UnmarshalJSON(data []byte) error {
+	var value string
+	if string(data) == "null" {
+		*d = Date{}
+		return nil
+	}
+	if err := json.Unmarshal(data, &value); err != nil {
+		return err
+	}
+	if value == "" {
+		*d = Date{}
+		return nil
+	}
+	// Some endpoints return full timestamps for date fields
+	if len(value) > len(dateLayout) {
+		t, err := ParseTimestamp(value)
+		if err != nil {
+			return fmt.Errorf("invalid date %q", value)
+		}
+		*d = DateOf(t.In(BrazilLocation))
+		return nil
+	}
+	parsed, err := ParseDate(value)
+	if err != nil {
+		return err
+	}
+	*d = parsed
+	return nil
+}
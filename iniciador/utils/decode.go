@@ -0,0 +1,229 @@
+package utils
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"reflect"
+	"sort"
+	"strings"
+	"sync"
+)
+
+var unmarshalerType = reflect.TypeOf((*json.Unmarshaler)(nil)).Elem()
+
+// DecodeReport lists the differences between a response body and the type it
+// was decoded into. Fields are identified by JSON path, e.g. "data[0].ispb".
+type DecodeReport struct {
+	Type          string   `json:"type"`
+	UnknownFields []string `json:"unknownFields,omitempty"`
+	MissingFields []string `json:"missingFields,omitempty"`
+}
+
+func (r DecodeReport) IsEmpty() bool {
+	return len(r.UnknownFields) == 0 && len(r.MissingFields) == 0
+}
+
+// StrictDecodingHandler receives the report of every response that has
+// unknown or missing fields.
+type StrictDecodingHandler func(report DecodeReport)
+
+var strictDecoding struct {
+	sync.RWMutex
+	handler StrictDecodingHandler
+}
+
+// EnableStrictDecoding makes HandleResponse compare every successful response
+// with its type, calling handler when the body has fields the type does not
+// declare, or lacks fields the type requires (those without omitempty).
+func EnableStrictDecoding(handler StrictDecodingHandler) {
+	strictDecoding.Lock()
+	defer strictDecoding.Unlock()
+	strictDecoding.handler = handler
+}
+
+func DisableStrictDecoding() {
+	EnableStrictDecoding(nil)
+}
+
+// LogDecodeReports returns a StrictDecodingHandler that writes reports to logger.
+func LogDecodeReports(logger *log.Logger) StrictDecodingHandler {
+	return func(report DecodeReport) {
+		logger.Printf("strict decoding of %s: unknown fields %v, missing fields %v", report.Type, report.UnknownFields, report.MissingFields)
+	}
+}
+
+// UnknownFields keeps the fields of a response that its type does not
+// declare. Response types embed it, and HandleResponse fills it in, so fields
+// added to the API are not lost. The fields are held by pointer, so that the
+// response types stay comparable with == and usable as map keys.
+type UnknownFields struct {
+	unknown *unknownFieldSet
+}
+
+type unknownFieldSet struct {
+	fields map[string]json.RawMessage
+}
+
+// Unknown returns the undeclared fields keyed by JSON name, or nil when the
+// response had none.
+func (u UnknownFields) Unknown() map[string]json.RawMessage {
+	if u.unknown == nil {
+		return nil
+	}
+	return u.unknown.fields
+}
+
+func (u *UnknownFields) setUnknownFields(fields map[string]json.RawMessage) {
+	u.unknown = &unknownFieldSet{fields: fields}
+}
+
+type unknownFieldsSetter interface {
+	setUnknownFields(fields map[string]json.RawMessage)
+}
+
+// inspectResponse preserves the unknown fields of output and, in strict
+// mode, reports them along with the missing required fields.
+func inspectResponse(body []byte, output interface{}) {
+	value := reflect.ValueOf(output)
+	if value.Kind() != reflect.Ptr || value.IsNil() {
+		return
+	}
+
+	report := DecodeReport{Type: value.Elem().Type().String()}
+	inspectValue(value.Elem(), body, "", &report)
+	sort.Strings(report.UnknownFields)
+	sort.Strings(report.MissingFields)
+
+	strictDecoding.RLock()
+	handler := strictDecoding.handler
+	strictDecoding.RUnlock()
+
+	if handler != nil && !report.IsEmpty() {
+		handler(report)
+	}
+}
+
+func inspectValue(v reflect.Value, raw json.RawMessage, path string, report *DecodeReport) {
+	for v.Kind() == reflect.Ptr {
+		if v.IsNil() {
+			return
+		}
+		v = v.Elem()
+	}
+	if v.CanAddr() && v.Addr().Type().Implements(unmarshalerType) {
+		return
+	}
+
+	switch v.Kind() {
+	case reflect.Struct:
+		inspectStruct(v, raw, path, report)
+	case reflect.Slice, reflect.Array:
+		var items []json.RawMessage
+		if json.Unmarshal(raw, &items) != nil {
+			return
+		}
+		for i := 0; i < len(items) && i < v.Len(); i++ {
+			inspectValue(v.Index(i), items[i], fmt.Sprintf("%s[%d]", path, i), report)
+		}
+	}
+}
+
+type jsonField struct {
+	name       string
+	isRequired bool
+	value      reflect.Value
+}
+
+func inspectStruct(v reflect.Value, raw json.RawMessage, path string, report *DecodeReport) {
+	var object map[string]json.RawMessage
+	if json.Unmarshal(raw, &object) != nil {
+		return
+	}
+
+	matched := make(map[string]bool, len(object))
+	for _, field := range jsonFields(v) {
+		key, ok := matchKey(object, field.name)
+		if !ok {
+			if field.isRequired {
+				report.MissingFields = append(report.MissingFields, joinPath(path, field.name))
+			}
+			continue
+		}
+		matched[key] = true
+		inspectValue(field.value, object[key], joinPath(path, field.name), report)
+	}
+
+	unknown := make(map[string]json.RawMessage)
+	for key, value := range object {
+		if !matched[key] {
+			unknown[key] = value
+			report.UnknownFields = append(report.UnknownFields, joinPath(path, key))
+		}
+	}
+	if len(unknown) > 0 && v.CanAddr() && v.Addr().CanInterface() {
+		if setter, ok := v.Addr().Interface().(unknownFieldsSetter); ok {
+			setter.setUnknownFields(unknown)
+		}
+	}
+}
+
+// jsonFields lists the fields encoding/json decodes into, flattening
+// embedded structs.
+func jsonFields(v reflect.Value) []jsonField {
+	var fields []jsonField
+	for i := 0; i < v.NumField(); i++ {
+		field := v.Type().Field(i)
+		if field.PkgPath != "" && !field.Anonymous {
+			continue
+		}
+
+		tag := field.Tag.Get("json")
+		if tag == "-" {
+			continue
+		}
+		options := strings.Split(tag, ",")
+		name := options[0]
+
+		if field.Anonymous && name == "" {
+			embedded := reflect.Indirect(v.Field(i))
+			if embedded.Kind() == reflect.Struct {
+				fields = append(fields, jsonFields(embedded)...)
+			}
+			continue
+		}
+		if name == "" {
+			name = field.Name
+		}
+
+		isRequired := true
+		for _, option := range options[1:] {
+			if option == "omitempty" {
+				isRequired = false
+			}
+		}
+		fields = append(fields, jsonField{name: name, isRequired: isRequired, value: v.Field(i)})
+	}
+	return fields
+}
+
+// matchKey finds name in object the way encoding/json does: an exact match,
+// or else a case-insensitive one.
+func matchKey(object map[string]json.RawMessage, name string) (string, bool) {
+	if _, ok := object[name]; ok {
+		return name, true
+	}
+	for key := range object {
+		if strings.EqualFold(key, name) {
+			return key, true
+		}
+	}
+	return "", false
+}
+
+func joinPath(path, name string) string {
+	if path == "" {
+		return name
+	}
+	return path + "." + name
+}
@@ -0,0 +1,105 @@
+package utils
+
+import (
+	"regexp"
+	"strings"
+)
+
+// OnlyDigits removes every character that is not a digit, such as the
+// punctuation of formatted CPF and CNPJ numbers.
+func OnlyDigits(value string) string {
+	return strings.Map(func(r rune) rune {
+		if r >= '0' && r <= '9' {
+			return r
+		}
+		return -1
+	}, value)
+}
+
+func IsDigits(value string) bool {
+	return value != "" && OnlyDigits(value) == value
+}
+
+var (
+	cpfPattern  = regexp.MustCompile(`^(\d{11}|\d{3}\.\d{3}\.\d{3}-\d{2})$`)
+	cnpjPattern = regexp.MustCompile(`^([0-9A-Z]{12}\d{2}|[0-9A-Z]{2}\.[0-9A-Z]{3}\.[0-9A-Z]{3}/[0-9A-Z]{4}-\d{2})$`)
+)
+
+// NormalizeTaxID removes the punctuation of a formatted CPF or CNPJ,
+// keeping the letters of alphanumeric CNPJs.
+func NormalizeTaxID(taxID string) string {
+	return strings.Map(func(r rune) rune {
+		if r == '.' || r == '/' || r == '-' {
+			return -1
+		}
+		return r
+	}, taxID)
+}
+
+// IsValidCPF checks the length and check digits of a CPF, either bare digits
+// or formatted as "529.982.247-25".
+func IsValidCPF(cpf string) bool {
+	if !cpfPattern.MatchString(cpf) {
+		return false
+	}
+	digits := NormalizeTaxID(cpf)
+	if isRepeated(digits) {
+		return false
+	}
+	return checkDigit(digits[:9], []int{10, 9, 8, 7, 6, 5, 4, 3, 2}) == digits[9] &&
+		checkDigit(digits[:10], []int{11, 10, 9, 8, 7, 6, 5, 4, 3, 2}) == digits[10]
+}
+
+// IsValidCNPJ checks the length and check digits of a CNPJ, either bare or
+// formatted as "11.222.333/0001-81". The first twelve characters may be
+// uppercase letters, as in the alphanumeric CNPJs issued since July 2026,
+// which are valued as their ASCII code minus 48.
+func IsValidCNPJ(cnpj string) bool {
+	if !cnpjPattern.MatchString(cnpj) {
+		return false
+	}
+	digits := NormalizeTaxID(cnpj)
+	if isRepeated(digits) {
+		return false
+	}
+	return checkDigit(digits[:12], []int{5, 4, 3, 2, 9, 8, 7, 6, 5, 4, 3, 2}) == digits[12] &&
+		checkDigit(digits[:13], []int{6, 5, 4, 3, 2, 9, 8, 7, 6, 5, 4, 3, 2}) == digits[13]
+}
+
+// IsValidTaxID reports whether taxID is a valid CPF or CNPJ.
+func IsValidTaxID(taxID string) bool {
+	return IsValidCPF(taxID) || IsValidCNPJ(taxID)
+}
+
+// MaskTaxID formats a CPF or CNPJ hiding its first and last digits, as in
+// receipts: "***.982.247-**" and "**.222.333/0001-**". Other values are
+// masked entirely.
+func MaskTaxID(taxID string) string {
+	digits := NormalizeTaxID(taxID)
+	switch len(digits) {
+	case 11:
+		return "***." + digits[3:6] + "." + digits[6:9] + "-**"
+	case 14:
+		return "**." + digits[2:5] + "." + digits[5:8] + "/" + digits[8:12] + "-**"
+	default:
+		return strings.Repeat("*", len(taxID))
+	}
+}
+
+// checkDigit computes a modulo 11 check digit, valuing each character as its
+// ASCII code minus 48, so that digits keep their value.
+func checkDigit(digits string, weights []int) byte {
+	sum := 0
+	for i, weight := range weights {
+		sum += int(digits[i]-'0') * weight
+	}
+	rest := sum % 11
+	if rest < 2 {
+		return '0'
+	}
+	return byte('0' + 11 - rest)
+}
+
+func isRepeated(digits string) bool {
+	return strings.Count(digits, digits[:1]) == len(digits)
+}
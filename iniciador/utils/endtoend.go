@@ -0,0 +1,143 @@
+package utils
+
+import (
+	"crypto/rand"
+	"fmt"
+	"math/big"
+	"time"
+)
+
+const (
+	EndToEndIDLength = 32
+
+	endToEndIDPrefix     = "E"
+	endToEndIDTimeLayout = "200601021504"
+	endToEndIDSequence   = 11
+
+	// MaxTransactionIdentificationLength is the longest txid of any method;
+	// dynamic QR codes require at least MinDynamicTransactionIdentificationLength.
+	MaxTransactionIdentificationLength        = 35
+	MinDynamicTransactionIdentificationLength = 26
+	MaxStaticTransactionIdentificationLength  = 25
+)
+
+const alphanumerics = "ABCDEFGHIJKLMNOPQRSTUVWXYZabcdefghijklmnopqrstuvwxyz0123456789"
+
+// EndToEndID is a parsed Pix EndToEndID: "E", the ISPB of the institution
+// that originated the payment, its UTC timestamp to the minute as
+// yyyyMMddHHmm and an 11 character alphanumeric sequence.
+type EndToEndID struct {
+	ISPB      string
+	Timestamp time.Time
+	Sequence  string
+}
+
+// ParseEndToEndID validates the structure of id and returns its parts.
+func ParseEndToEndID(id string) (*EndToEndID, error) {
+	if len(id) != EndToEndIDLength {
+		return nil, fmt.Errorf("endToEndId must have %d characters, got %d", EndToEndIDLength, len(id))
+	}
+	if id[:1] != endToEndIDPrefix {
+		return nil, fmt.Errorf("endToEndId must start with %q", endToEndIDPrefix)
+	}
+
+	ispb, timestamp, sequence := id[1:9], id[9:21], id[21:]
+	if !IsDigits(ispb) {
+		return nil, fmt.Errorf("endToEndId ISPB %q must have 8 digits", ispb)
+	}
+	if !IsDigits(timestamp) {
+		return nil, fmt.Errorf("endToEndId timestamp %q must have 12 digits", timestamp)
+	}
+	parsed, err := time.Parse(endToEndIDTimeLayout, timestamp)
+	if err != nil {
+		return nil, fmt.Errorf("endToEndId timestamp %q is not a valid yyyyMMddHHmm date", timestamp)
+	}
+	if !isAlphanumeric(sequence) {
+		return nil, fmt.Errorf("endToEndId sequence %q must be alphanumeric", sequence)
+	}
+
+	return &EndToEndID{ISPB: ispb, Timestamp: parsed, Sequence: sequence}, nil
+}
+
+func IsValidEndToEndID(id string) bool {
+	_, err := ParseEndToEndID(id)
+	return err == nil
+}
+
+func (e EndToEndID) String() string {
+	return endToEndIDPrefix + e.ISPB + e.Timestamp.UTC().Format(endToEndIDTimeLayout) + e.Sequence
+}
+
+// NewEndToEndID generates an EndToEndID for ispb at t with a random sequence,
+// e.g. for test data.
+func NewEndToEndID(ispb string, t time.Time) (string, error) {
+	if len(ispb) != 8 || !IsDigits(ispb) {
+		return "", fmt.Errorf("ISPB %q must have 8 digits", ispb)
+	}
+	sequence, err := randomAlphanumeric(endToEndIDSequence)
+	if err != nil {
+		return "", err
+	}
+	return EndToEndID{ISPB: ispb, Timestamp: t, Sequence: sequence}.String(), nil
+}
+
+// ValidateTransactionIdentification checks the txid of a payment of method:
+// up to 35 alphanumeric characters, at least 26 for dynamic QR codes and up
+// to 25 for the other methods. An empty txid is valid.
+func ValidateTransactionIdentification(method PaymentMethod, txid string) error {
+	if txid == "" {
+		return nil
+	}
+	if !isAlphanumeric(txid) {
+		return fmt.Errorf("transactionIdentification must be alphanumeric")
+	}
+
+	switch method {
+	case PixDynamicQRCode:
+		if len(txid) < MinDynamicTransactionIdentificationLength || len(txid) > MaxTransactionIdentificationLength {
+			return fmt.Errorf("transactionIdentification of %s payments must have %d to %d characters", method, MinDynamicTransactionIdentificationLength, MaxTransactionIdentificationLength)
+		}
+	case "":
+		if len(txid) > MaxTransactionIdentificationLength {
+			return fmt.Errorf("transactionIdentification must have at most %d characters", MaxTransactionIdentificationLength)
+		}
+	default:
+		if len(txid) > MaxStaticTransactionIdentificationLength {
+			return fmt.Errorf("transactionIdentification of %s payments must have at most %d characters", method, MaxStaticTransactionIdentificationLength)
+		}
+	}
+	return nil
+}
+
+// NewTransactionIdentification generates a random txid of length characters.
+func NewTransactionIdentification(length int) (string, error) {
+	if length < 1 || length > MaxTransactionIdentificationLength {
+		return "", fmt.Errorf("length must be between 1 and %d", MaxTransactionIdentificationLength)
+	}
+	return randomAlphanumeric(length)
+}
+
+func isAlphanumeric(value string) bool {
+	if value == "" {
+		return false
+	}
+	for _, r := range value {
+		if !(r >= '0' && r <= '9' || r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z') {
+			return false
+		}
+	}
+	return true
+}
+
+func randomAlphanumeric(length int) (string, error) {
+	result := make([]byte, length)
+	max := big.NewInt(int64(len(alphanumerics)))
+	for i := range result {
+		n, err := rand.Int(rand.Reader, max)
+		if err != nil {
+			return "", err
+		}
+		result[i] = alphanumerics[n.Int64()]
+	}
+	return string(result), nil
+}
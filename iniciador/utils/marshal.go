@@ -0,0 +1,159 @@
+package utils
+
+import (
+	"encoding/json"
+	"reflect"
+	"strings"
+)
+
+var marshalerType = reflect.TypeOf((*json.Marshaler)(nil)).Elem()
+
+// MarshalWithoutEmptyFields encodes payload as JSON containing only the fields
+// that were set, at any depth. A field is set when it is a non-nil pointer,
+// even to a zero value, or a non-zero value. Nested structs with no set fields
+// are dropped, as are empty maps and slices.
+func MarshalWithoutEmptyFields(payload interface{}) ([]byte, error) {
+	return MarshalWithFields(payload)
+}
+
+// MarshalWithFields works like MarshalWithoutEmptyFields, but always sends
+// the given fields, identified by JSON path such as "amount" or
+// "debtor.number". Unset masked fields are sent as their zero value, or null
+// for nil pointers, maps and slices, which is how an explicit zero or null is
+// sent. A masked nested field is only sent when its parent object is.
+func MarshalWithFields(payload interface{}, fields ...string) ([]byte, error) {
+	mask := make(map[string]bool, len(fields))
+	for _, field := range fields {
+		mask[field] = true
+	}
+
+	value, isSet, err := presentValue(reflect.ValueOf(payload), "", mask, false)
+	if err != nil {
+		return nil, err
+	}
+	if !isSet {
+		if reflect.Indirect(reflect.ValueOf(payload)).Kind() == reflect.Struct {
+			return []byte("{}"), nil
+		}
+		return []byte("null"), nil
+	}
+	return json.Marshal(value)
+}
+
+// presentValue returns the JSON-ready form of v and whether it is set.
+// Values reached through a non-nil pointer are explicit and always set.
+func presentValue(v reflect.Value, path string, mask map[string]bool, isExplicit bool) (interface{}, bool, error) {
+	if !v.IsValid() {
+		return nil, false, nil
+	}
+
+	switch v.Kind() {
+	case reflect.Ptr, reflect.Interface:
+		if v.IsNil() {
+			return nil, false, nil
+		}
+		return presentValue(v.Elem(), path, mask, true)
+	}
+
+	if v.Type().Implements(marshalerType) {
+		data, err := json.Marshal(v.Interface())
+		if err != nil {
+			return nil, false, err
+		}
+		if string(data) == "null" {
+			return nil, false, nil
+		}
+		return json.RawMessage(data), true, nil
+	}
+
+	switch v.Kind() {
+	case reflect.Struct:
+		object := make(map[string]interface{})
+		err := presentFields(v, path, mask, object)
+		if err != nil {
+			return nil, false, err
+		}
+		return object, len(object) > 0 || isExplicit, nil
+	case reflect.Map:
+		if v.IsNil() || (v.Len() == 0 && !isExplicit) {
+			return nil, false, nil
+		}
+		data, err := json.Marshal(v.Interface())
+		if err != nil {
+			return nil, false, err
+		}
+		return json.RawMessage(data), true, nil
+	case reflect.Slice, reflect.Array:
+		if (v.Kind() == reflect.Slice && v.IsNil()) || (v.Len() == 0 && !isExplicit) {
+			return nil, false, nil
+		}
+		items := make([]interface{}, v.Len())
+		for i := range items {
+			item, _, err := presentValue(v.Index(i), path, mask, true)
+			if err != nil {
+				return nil, false, err
+			}
+			items[i] = item
+		}
+		return items, true, nil
+	default:
+		if v.IsZero() && !isExplicit {
+			return nil, false, nil
+		}
+		return v.Interface(), true, nil
+	}
+}
+
+func presentFields(v reflect.Value, path string, mask map[string]bool, object map[string]interface{}) error {
+	for i := 0; i < v.NumField(); i++ {
+		field := v.Type().Field(i)
+		if field.PkgPath != "" && !field.Anonymous {
+			continue
+		}
+
+		tag := field.Tag.Get("json")
+		if tag == "-" {
+			continue
+		}
+		name := strings.Split(tag, ",")[0]
+
+		fieldValue := v.Field(i)
+		if field.Anonymous && name == "" {
+			embedded := reflect.Indirect(fieldValue)
+			if embedded.Kind() == reflect.Struct {
+				err := presentFields(embedded, path, mask, object)
+				if err != nil {
+					return err
+				}
+				continue
+			}
+		}
+		if !fieldValue.CanInterface() {
+			continue
+		}
+		if name == "" {
+			name = field.Name
+		}
+
+		fieldPath := name
+		if path != "" {
+			fieldPath = path + "." + name
+		}
+
+		value, isSet, err := presentValue(fieldValue, fieldPath, mask, false)
+		if err != nil {
+			return err
+		}
+		if !isSet && mask[fieldPath] {
+			data, err := json.Marshal(fieldValue.Interface())
+			if err != nil {
+				return err
+			}
+			value, isSet = json.RawMessage(data), true
+		}
+		if isSet {
+			object[name] = value
+		}
+	}
+	return nil
+}
@@ -0,0 +1,36 @@
+package utils
+
+// PaymentMethod is how the Pix payment is initiated. It lives in utils so the
+// token payload can use it; the payments package re-exports it.
+type PaymentMethod string
+
+const (
+	// PixManual pays to a creditor account typed in manually.
+	PixManual PaymentMethod = "PIX_MANU_AUTO"
+	// PixDict pays to a DICT key (CPF, CNPJ, e-mail, phone or random key).
+	PixDict PaymentMethod = "PIX_DICT"
+	// PixStaticQRCode pays a static QR code (QRES).
+	PixStaticQRCode PaymentMethod = "PIX_QRES"
+	// PixDynamicQRCode pays a dynamic QR code (QRDN).
+	PixDynamicQRCode PaymentMethod = "PIX_QRDN"
+	// PixInitiator pays to a creditor account provided by the initiator (INIC).
+	PixInitiator PaymentMethod = "PIX_INIC"
+)
+
+var paymentMethods = []PaymentMethod{PixManual, PixDict, PixStaticQRCode, PixDynamicQRCode, PixInitiator}
+
+// PaymentMethods returns every supported payment method.
+func PaymentMethods() []PaymentMethod {
+	methods := make([]PaymentMethod, len(paymentMethods))
+	copy(methods, paymentMethods)
+	return methods
+}
+
+func (m PaymentMethod) IsValid() bool {
+	for _, method := range paymentMethods {
+		if m == method {
+			return true
+		}
+	}
+	return false
+}
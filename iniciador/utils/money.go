@@ -0,0 +1,45 @@
+package utils
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+)
+
+// AmountCents converts an amount in cents, as used across the API, to an
+// integer, rounding away the float error of values such as 0.1 + 0.2.
+func AmountCents(amount float64) int64 {
+	return int64(math.Round(amount))
+}
+
+// FormatDecimal formats an amount in cents with two decimal places and a
+// point separator, e.g. 123456 as "1234.56", without float rounding.
+func FormatDecimal(amount float64) string {
+	cents := AmountCents(amount)
+	sign := ""
+	if cents < 0 {
+		sign, cents = "-", -cents
+	}
+	return fmt.Sprintf("%s%d.%02d", sign, cents/100, cents%100)
+}
+
+// FormatBRL formats an amount in cents as Brazilian reais, e.g. 123456 as
+// "R$ 1.234,56".
+func FormatBRL(amount float64) string {
+	cents := AmountCents(amount)
+	sign := ""
+	if cents < 0 {
+		sign, cents = "-", -cents
+	}
+
+	units := strconv.FormatInt(cents/100, 10)
+	grouped := make([]byte, 0, len(units)+len(units)/3)
+	for i := range units {
+		if i > 0 && (len(units)-i)%3 == 0 {
+			grouped = append(grouped, '.')
+		}
+		grouped = append(grouped, units[i])
+	}
+
+	return fmt.Sprintf("%sR$ %s,%02d", sign, grouped, cents%100)
+}
@@ -0,0 +1,78 @@
+package utils
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+)
+
+// timestampLayouts are tried in order when decoding a Timestamp. Layouts
+// without an offset are read as Brazil time.
+var timestampLayouts = []string{
+	time.RFC3339Nano,
+	"2006-01-02T15:04:05.999999999",
+	"2006-01-02 15:04:05",
+}
+
+// Timestamp is an instant returned by the API. It embeds time.Time and
+// serializes as RFC 3339, or null when zero.
+type Timestamp struct {
+	time.Time
+}
+
+func NewTimestamp(t time.Time) Timestamp {
+	return Timestamp{Time: t}
+}
+
+// InBrazil returns the instant in America/Sao_Paulo.
+func (t Timestamp) InBrazil() time.Time {
+	return t.Time.In(BrazilLocation)
+}
+
+// BrazilDate returns the civil date of the instant in America/Sao_Paulo.
+func (t Timestamp) BrazilDate() Date {
+	if t.IsZero() {
+		return Date{}
+	}
+	return DateOf(t.InBrazil())
+}
+
+func (t Timestamp) MarshalJSON() ([]byte, error) {
+	if t.IsZero() {
+		return []byte("null"), nil
+	}
+	return json.Marshal(t.Time.Format(time.RFC3339Nano))
+}
+
+func (t *Timestamp) UnmarshalJSON(data []byte) error {
+	var value string
+	if string(data) == "null" {
+		*t = Timestamp{}
+		return nil
+	}
+	if err := json.Unmarshal(data, &value); err != nil {
+		return err
+	}
+	if value == "" {
+		*t = Timestamp{}
+		return nil
+	}
+	parsed, err := ParseTimestamp(value)
+	if err != nil {
+		return err
+	}
+	*t = Timestamp{Time: parsed}
+	return nil
+}
+
+// ParseTimestamp parses value with the layouts accepted by Timestamp,
+// reading values without an offset as Brazil time.
+func ParseTimestamp(value string) (time.Time, error) {
+	for _, layout := range timestampLayouts {
+		parsed, err := time.ParseInLocation(layout, value, BrazilLocation)
+		if err == nil {
+			return parsed, nil
+		}
+	}
+	return time.Time{}, fmt.Errorf("invalid timestamp %q", value)
+}
@@ -1,12 +1,15 @@
 package utils
 
 import (
+	"bytes"
 	"encoding/base64"
 	"encoding/json"
 	"fmt"
+	"io"
 	"io/ioutil"
 	"net/http"
 	"strings"
+	"time"
 )
 
 func SetEnvironment(environment string) string {
@@ -24,6 +27,8 @@ func SetEnvironment(environment string) string {
 	}
 }
 
+// Error is the body of an error response. Timestamp is kept as sent, so
+// that an unexpected layout never hides the error itself.
 type Error struct {
 	ErrorCode  string   `json:"errorCode"`
 	Message    []string `json:"message"`
@@ -33,6 +38,11 @@ type Error struct {
 	Timestamp  string   `json:"timestamp"`
 }
 
+// Time parses Timestamp with the layouts accepted by Timestamp.
+func (e Error) Time() (time.Time, error) {
+	return ParseTimestamp(e.Timestamp)
+}
+
 func HandleResponse(response *http.Response, output interface{}) error {
 	defer response.Body.Close()
 
@@ -42,53 +52,38 @@ func HandleResponse(response *http.Response, output interface{}) error {
 	}
 
 	if response.StatusCode >= 200 && response.StatusCode <= 299 {
+		if output == nil || len(bodyBytes) == 0 {
+			return nil
+		}
 		err = json.Unmarshal(bodyBytes, output)
 		if err != nil {
 			return fmt.Errorf("failed to decode response body: %v", err)
 		}
+		inspectResponse(bodyBytes, output)
 		return nil
 	}
 
+	// Bodies that are not JSON, such as gateway error pages, still report
+	// the status code.
 	var errResponse Error
-	err = json.Unmarshal(bodyBytes, &errResponse)
-	if err != nil {
-		return fmt.Errorf("failed to decode error response: %v", err)
-	}
+	_ = json.Unmarshal(bodyBytes, &errResponse)
 
-	if len(errResponse.Message) > 0 {
-		return fmt.Errorf("request failed with status code %d: %s", errResponse.StatusCode, strings.Join(errResponse.Message, ", "))
-	}
-
-	return fmt.Errorf("request failed with status code %d", response.StatusCode)
+	return &APIError{StatusCode: response.StatusCode, Response: errResponse}
 }
 
-func MarshalWithoutEmptyFields(payload interface{}) ([]byte, error) {
-	data, err := json.Marshal(payload)
-	if err != nil {
-		return nil, err
-	}
-
-	var m map[string]interface{}
-	err = json.Unmarshal(data, &m)
-	if err != nil {
-		return nil, err
-	}
+// APIError is returned by HandleResponse when the API answers with a non-2xx
+// status code. StatusCode is the HTTP status and Response the decoded body.
+type APIError struct {
+	StatusCode int   `json:"statusCode"`
+	Response   Error `json:"response"`
+}
 
-	nonEmptyFields := make(map[string]interface{})
-	for k, v := range m {
-		if v != nil {
-			switch value := v.(type) {
-			case string:
-				if value != "" {
-					nonEmptyFields[k] = v
-				}
-			default:
-				nonEmptyFields[k] = v
-			}
-		}
+func (e *APIError) Error() string {
+	if len(e.Response.Message) > 0 {
+		return fmt.Sprintf("request failed with status code %d: %s", e.Response.StatusCode, strings.Join(e.Response.Message, ", "))
 	}
 
-	return json.Marshal(nonEmptyFields)
+	return fmt.Sprintf("request failed with status code %d", e.StatusCode)
 }
 
 type TokenData struct {
@@ -101,15 +96,18 @@ type TokenData struct {
 }
 
 type PayloadData struct {
-	ID             string   `json:"id"`
-	CreatedAt      string   `json:"createdAt"`
-	Date           string   `json:"date"`
-	Status         string   `json:"status"`
-	ClientID       string   `json:"clientId"`
-	CustomerID     string   `json:"customerId"`
-	Fee            int      `json:"fee"`
-	Creditor       Creditor `json:"creditor"`
-	PaymentMethods []string `json:"paymentMethods"`
+	ID             string          `json:"id"`
+	CreatedAt      string          `json:"createdAt"`
+	Date           string          `json:"date"`
+	Status         string          `json:"status"`
+	ClientID       string          `json:"clientId"`
+	CustomerID     string          `json:"customerId"`
+	Fee            int             `json:"fee"`
+	Creditor       Creditor        `json:"creditor"`
+	PaymentMethods []PaymentMethod `json:"paymentMethods"`
+	Amount         float64         `json:"amount,omitempty"`
+	Description    string          `json:"description,omitempty"`
+	ExternalID     string          `json:"externalId,omitempty"`
 }
 
 type Creditor struct {
@@ -121,31 +119,75 @@ type Creditor struct {
 	AccountType string `json:"accountType"`
 }
 
-func ExtractPaymentIDFromJWTPayload(token string) (string, error) {
+// ParseTokenData decodes the claims of an access token. The signature is not
+// verified; the token is trusted because it was issued to this client.
+func ParseTokenData(token string) (*TokenData, error) {
 	// Split the token into its parts: header, payload, and signature
 	parts := strings.Split(token, ".")
 
 	if len(parts) != 3 {
-		return "", fmt.Errorf("Invalid JWT token.")
+		return nil, fmt.Errorf("Invalid JWT token.")
 	}
 
 	// Decode the payload part from Base64
 	payloadBytes, err := base64.RawURLEncoding.DecodeString(parts[1])
 	if err != nil {
-		return "", fmt.Errorf("Error decoding payload: %v", err)
+		return nil, fmt.Errorf("Error decoding payload: %v", err)
+	}
+
+	var tokenData TokenData
+	err = json.Unmarshal(payloadBytes, &tokenData)
+	if err != nil {
+		return nil, fmt.Errorf("Error decoding JSON payload: %v", err)
 	}
 
-	// Convert the decoded payload into a string
-	payload := string(payloadBytes)
+	return &tokenData, nil
+}
+
+func (t *TokenData) IssuedAt() time.Time {
+	return time.Unix(t.Iat, 0)
+}
+
+func (t *TokenData) ExpiresAt() time.Time {
+	return time.Unix(t.Exp, 0)
+}
 
-	var payloadData TokenData
-	err = json.Unmarshal([]byte(payload), &payloadData)
+func ExtractPaymentIDFromJWTPayload(token string) (string, error) {
+	tokenData, err := ParseTokenData(token)
 	if err != nil {
-		fmt.Println("Error decoding JSON payload:", err)
 		return "", err
 	}
 
-	id := payloadData.Payload.ID
+	return tokenData.Payload.ID, nil
+}
+
+// DoRequest sends an authenticated JSON request and decodes the response into output.
+// The body, when not nil, is encoded with MarshalWithoutEmptyFields.
+func DoRequest(method, url, accessToken string, body interface{}, output interface{}) error {
+	var requestBody io.Reader
+	if body != nil {
+		payload, err := MarshalWithoutEmptyFields(body)
+		if err != nil {
+			return err
+		}
+		requestBody = bytes.NewBuffer(payload)
+	}
+
+	req, err := http.NewRequest(method, url, requestBody)
+	if err != nil {
+		return err
+	}
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	req.Header.Set("Authorization", "Bearer "+accessToken)
+
+	client := http.DefaultClient
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
 
-	return id, nil
+	return HandleResponse(resp, output)
 }
@@ -0,0 +1,175 @@
+package webhooks
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+
+	"iniciador-sdk/iniciador/auth"
+	"iniciador-sdk/iniciador/utils"
+)
+
+// TestEvent is the type of the events sent by TestSubscription. They carry a
+// sample payment status.
+const TestEvent = "WEBHOOK_TEST"
+
+type SubscriptionStatus string
+
+const (
+	Active   SubscriptionStatus = "ACTIVE"
+	Inactive SubscriptionStatus = "INACTIVE"
+)
+
+// Subscription registers a callback URL for the events of the authenticated
+// client. Secret, used to sign the notifications, is only returned when the
+// subscription is created and when it is rotated.
+type Subscription struct {
+	utils.UnknownFields `json:"-"`
+
+	ID          string             `json:"id"`
+	CreatedAt   utils.Timestamp    `json:"createdAt"`
+	UpdatedAt   utils.Timestamp    `json:"updatedAt,omitempty"`
+	URL         string             `json:"url"`
+	Events      []string           `json:"events"`
+	Status      SubscriptionStatus `json:"status"`
+	Description string             `json:"description,omitempty"`
+	Secret      string             `json:"secret,omitempty"`
+}
+
+// SubscriptionInput creates or updates a subscription. On update, only the
+// fields set are changed.
+type SubscriptionInput struct {
+	URL         string             `json:"url,omitempty"`
+	Events      []string           `json:"events,omitempty"`
+	Status      SubscriptionStatus `json:"status,omitempty"`
+	Description string             `json:"description,omitempty"`
+}
+
+type SubscriptionsOutput struct {
+	Data []Subscription `json:"data"`
+}
+
+// TestResult is the outcome of a test notification sent to a subscription.
+type TestResult struct {
+	EventID    string `json:"eventId"`
+	StatusCode int    `json:"statusCode"`
+	Success    bool   `json:"success"`
+	DurationMs int64  `json:"durationMs,omitempty"`
+	Error      string `json:"error,omitempty"`
+}
+
+type testRequest struct {
+	Event string `json:"event"`
+}
+
+// Validate checks the input of a new subscription, or of an update when
+// isUpdate is true.
+func (s *SubscriptionInput) Validate(isUpdate bool) error {
+	if s.URL == "" && !isUpdate {
+		return fmt.Errorf("url is required")
+	}
+	if s.URL != "" {
+		parsed, err := url.Parse(s.URL)
+		if err != nil || (parsed.Scheme != "https" && parsed.Scheme != "http") || parsed.Host == "" {
+			return fmt.Errorf("url must be an absolute http or https URL")
+		}
+	}
+	if len(s.Events) == 0 && !isUpdate {
+		return fmt.Errorf("at least one event is required")
+	}
+	for _, event := range s.Events {
+		if event == "" {
+			return fmt.Errorf("events cannot be empty")
+		}
+	}
+	if s.Status != "" && s.Status != Active && s.Status != Inactive {
+		return fmt.Errorf("invalid status %q", s.Status)
+	}
+	return nil
+}
+
+func CreateSubscription(accessToken string, input *SubscriptionInput, authClient *auth.AuthClient) (*Subscription, error) {
+	err := input.Validate(false)
+	if err != nil {
+		return nil, err
+	}
+
+	var output Subscription
+	url := fmt.Sprintf("%s/webhooks", authClient.Environment)
+	err = utils.DoRequest(http.MethodPost, url, accessToken, input, &output)
+	if err != nil {
+		return nil, err
+	}
+
+	return &output, nil
+}
+
+func GetSubscription(accessToken, subscriptionID string, authClient *auth.AuthClient) (*Subscription, error) {
+	var output Subscription
+	url := fmt.Sprintf("%s/webhooks/%s", authClient.Environment, subscriptionID)
+	err := utils.DoRequest(http.MethodGet, url, accessToken, nil, &output)
+	if err != nil {
+		return nil, err
+	}
+
+	return &output, nil
+}
+
+func ListSubscriptions(accessToken string, authClient *auth.AuthClient) (*SubscriptionsOutput, error) {
+	var output SubscriptionsOutput
+	url := fmt.Sprintf("%s/webhooks", authClient.Environment)
+	err := utils.DoRequest(http.MethodGet, url, accessToken, nil, &output)
+	if err != nil {
+		return nil, err
+	}
+
+	return &output, nil
+}
+
+func UpdateSubscription(accessToken, subscriptionID string, input *SubscriptionInput, authClient *auth.AuthClient) (*Subscription, error) {
+	err := input.Validate(true)
+	if err != nil {
+		return nil, err
+	}
+
+	var output Subscription
+	url := fmt.Sprintf("%s/webhooks/%s", authClient.Environment, subscriptionID)
+	err = utils.DoRequest(http.MethodPatch, url, accessToken, input, &output)
+	if err != nil {
+		return nil, err
+	}
+
+	return &output, nil
+}
+
+func DeleteSubscription(accessToken, subscriptionID string, authClient *auth.AuthClient) error {
+	url := fmt.Sprintf("%s/webhooks/%s", authClient.Environment, subscriptionID)
+	return utils.DoRequest(http.MethodDelete, url, accessToken, nil, nil)
+}
+
+// TestSubscription asks the API to send a TestEvent notification to the
+// subscription URL and reports how the URL answered.
+func TestSubscription(accessToken, subscriptionID string, authClient *auth.AuthClient) (*TestResult, error) {
+	var output TestResult
+	url := fmt.Sprintf("%s/webhooks/%s/test", authClient.Environment, subscriptionID)
+	err := utils.DoRequest(http.MethodPost, url, accessToken, &testRequest{Event: TestEvent}, &output)
+	if err != nil {
+		return nil, err
+	}
+
+	return &output, nil
+}
+
+// RotateSecret replaces the signing secret of a subscription, returning it in
+// Secret. Until every receiver is updated, keep the old secret in
+// HMACVerifier.PreviousSecret.
+func RotateSecret(accessToken, subscriptionID string, authClient *auth.AuthClient) (*Subscription, error) {
+	var output Subscription
+	url := fmt.Sprintf("%s/webhooks/%s/rotate-secret", authClient.Environment, subscriptionID)
+	err := utils.DoRequest(http.MethodPost, url, accessToken, nil, &output)
+	if err != nil {
+		return nil, err
+	}
+
+	return &output, nil
+}
@@ -0,0 +1,252 @@
+package webhooks
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/hmac"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	DefaultSignatureHeader = "X-Webhook-Signature"
+	DefaultTimestampHeader = "X-Webhook-Timestamp"
+	DefaultTolerance       = 5 * time.Minute
+)
+
+// ErrInvalidSignature is returned by verifiers for notifications that are
+// not authentic or are too old.
+var ErrInvalidSignature = errors.New("invalid webhook signature")
+
+// Verifier checks the authenticity of a notification and returns the event
+// JSON it carries.
+type Verifier interface {
+	Verify(r *http.Request, body []byte) ([]byte, error)
+}
+
+// HMACVerifier verifies notifications whose signature header is
+// "sha256=" followed by the hex HMAC-SHA256, keyed with Secret, of the
+// timestamp header, a dot and the body. Notifications whose Unix timestamp is
+// further than Tolerance from now are rejected, so captured notifications
+// cannot be replayed later. PreviousSecret, when set, is also accepted while
+// the secret is being rotated.
+type HMACVerifier struct {
+	Secret          []byte
+	PreviousSecret  []byte
+	SignatureHeader string
+	TimestampHeader string
+	Tolerance       time.Duration
+}
+
+func (v *HMACVerifier) Verify(r *http.Request, body []byte) ([]byte, error) {
+	if len(v.Secret) == 0 {
+		return nil, fmt.Errorf("webhook secret is not configured")
+	}
+	signatureHeader := v.SignatureHeader
+	if signatureHeader == "" {
+		signatureHeader = DefaultSignatureHeader
+	}
+	timestampHeader := v.TimestampHeader
+	if timestampHeader == "" {
+		timestampHeader = DefaultTimestampHeader
+	}
+
+	timestamp := r.Header.Get(timestampHeader)
+	seconds, err := strconv.ParseInt(timestamp, 10, 64)
+	if err != nil {
+		return nil, fmt.Errorf("%w: invalid timestamp %q", ErrInvalidSignature, timestamp)
+	}
+	if !withinTolerance(time.Unix(seconds, 0), v.Tolerance) {
+		return nil, fmt.Errorf("%w: timestamp %s is outside the tolerance", ErrInvalidSignature, timestamp)
+	}
+
+	signature, err := hex.DecodeString(strings.TrimPrefix(r.Header.Get(signatureHeader), "sha256="))
+	if err != nil {
+		return nil, ErrInvalidSignature
+	}
+	if hmac.Equal(signature, Sign(v.Secret, timestamp, body)) {
+		return body, nil
+	}
+	if len(v.PreviousSecret) > 0 && hmac.Equal(signature, Sign(v.PreviousSecret, timestamp, body)) {
+		return body, nil
+	}
+
+	return nil, ErrInvalidSignature
+}
+
+// Sign computes the HMAC-SHA256 signature checked by HMACVerifier, e.g. to
+// send test notifications.
+func Sign(secret []byte, timestamp string, body []byte) []byte {
+	mac := hmac.New(sha256.New, secret)
+	mac.Write([]byte(timestamp))
+	mac.Write([]byte("."))
+	mac.Write(body)
+	return mac.Sum(nil)
+}
+
+// JWTVerifier verifies notifications whose body is a compact JWS with the
+// event as its payload. HS256 tokens are checked with Secret, RS256 and ES256
+// tokens with PublicKey; the algorithm must match the configured key. The exp
+// and iat claims, when present, are checked with Tolerance as leeway, and
+// Issuer and Audience, when set, must match.
+type JWTVerifier struct {
+	Secret    []byte
+	PublicKey crypto.PublicKey
+	Issuer    string
+	Audience  string
+	Tolerance time.Duration
+}
+
+type jwtHeader struct {
+	Alg string `json:"alg"`
+}
+
+type jwtClaims struct {
+	Iss string          `json:"iss"`
+	Aud json.RawMessage `json:"aud"`
+	Iat int64           `json:"iat"`
+	Exp int64           `json:"exp"`
+}
+
+func (v *JWTVerifier) Verify(r *http.Request, body []byte) ([]byte, error) {
+	parts := strings.Split(strings.TrimSpace(string(body)), ".")
+	if len(parts) != 3 {
+		return nil, fmt.Errorf("%w: body is not a JWT", ErrInvalidSignature)
+	}
+	headerBytes, err := base64.RawURLEncoding.DecodeString(parts[0])
+	if err != nil {
+		return nil, fmt.Errorf("%w: invalid JWT header", ErrInvalidSignature)
+	}
+	payload, err := base64.RawURLEncoding.DecodeString(parts[1])
+	if err != nil {
+		return nil, fmt.Errorf("%w: invalid JWT payload", ErrInvalidSignature)
+	}
+	signature, err := base64.RawURLEncoding.DecodeString(parts[2])
+	if err != nil {
+		return nil, fmt.Errorf("%w: invalid JWT signature", ErrInvalidSignature)
+	}
+
+	var header jwtHeader
+	err = json.Unmarshal(headerBytes, &header)
+	if err != nil {
+		return nil, fmt.Errorf("%w: invalid JWT header", ErrInvalidSignature)
+	}
+	err = v.verifySignature(header.Alg, []byte(parts[0]+"."+parts[1]), signature)
+	if err != nil {
+		return nil, err
+	}
+
+	var claims jwtClaims
+	err = json.Unmarshal(payload, &claims)
+	if err != nil {
+		return nil, fmt.Errorf("%w: invalid JWT claims", ErrInvalidSignature)
+	}
+	err = v.verifyClaims(&claims)
+	if err != nil {
+		return nil, err
+	}
+
+	return payload, nil
+}
+
+func (v *JWTVerifier) verifySignature(alg string, signed, signature []byte) error {
+	digest := sha256.Sum256(signed)
+
+	switch alg {
+	case "HS256":
+		if len(v.Secret) == 0 {
+			break
+		}
+		mac := hmac.New(sha256.New, v.Secret)
+		mac.Write(signed)
+		if hmac.Equal(signature, mac.Sum(nil)) {
+			return nil
+		}
+		return ErrInvalidSignature
+	case "RS256":
+		publicKey, ok := v.PublicKey.(*rsa.PublicKey)
+		if !ok {
+			break
+		}
+		if rsa.VerifyPKCS1v15(publicKey, crypto.SHA256, digest[:], signature) == nil {
+			return nil
+		}
+		return ErrInvalidSignature
+	case "ES256":
+		publicKey, ok := v.PublicKey.(*ecdsa.PublicKey)
+		if !ok {
+			break
+		}
+		if len(signature) != 64 {
+			return ErrInvalidSignature
+		}
+		r := new(big.Int).SetBytes(signature[:32])
+		s := new(big.Int).SetBytes(signature[32:])
+		if ecdsa.Verify(publicKey, digest[:], r, s) {
+			return nil
+		}
+		return ErrInvalidSignature
+	}
+
+	return fmt.Errorf("%w: unsupported algorithm %q for the configured key", ErrInvalidSignature, alg)
+}
+
+func (v *JWTVerifier) verifyClaims(claims *jwtClaims) error {
+	tolerance := v.Tolerance
+	if tolerance == 0 {
+		tolerance = DefaultTolerance
+	}
+	now := time.Now()
+
+	if claims.Exp != 0 && now.After(time.Unix(claims.Exp, 0).Add(tolerance)) {
+		return fmt.Errorf("%w: token expired", ErrInvalidSignature)
+	}
+	if claims.Iat != 0 && time.Unix(claims.Iat, 0).After(now.Add(tolerance)) {
+		return fmt.Errorf("%w: token issued in the future", ErrInvalidSignature)
+	}
+	if v.Issuer != "" && claims.Iss != v.Issuer {
+		return fmt.Errorf("%w: unexpected issuer %q", ErrInvalidSignature, claims.Iss)
+	}
+	if v.Audience != "" && !hasAudience(claims.Aud, v.Audience) {
+		return fmt.Errorf("%w: unexpected audience", ErrInvalidSignature)
+	}
+	return nil
+}
+
+// hasAudience checks the aud claim, which is a string or a list of strings.
+func hasAudience(aud json.RawMessage, audience string) bool {
+	var single string
+	if json.Unmarshal(aud, &single) == nil {
+		return single == audience
+	}
+	var list []string
+	if json.Unmarshal(aud, &list) == nil {
+		for _, item := range list {
+			if item == audience {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func withinTolerance(t time.Time, tolerance time.Duration) bool {
+	if tolerance == 0 {
+		tolerance = DefaultTolerance
+	}
+	difference := time.Since(t)
+	if difference < 0 {
+		difference = -difference
+	}
+	return difference <= tolerance
+}
@@ -0,0 +1,246 @@
+package webhooks
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"sync"
+	"time"
+
+	"iniciador-sdk/iniciador/payments"
+	"iniciador-sdk/iniciador/utils"
+)
+
+// MaxBodySize is the largest notification body the Receiver reads.
+const MaxBodySize = 1 << 20
+
+const PaymentStatusChanged = "PAYMENT_STATUS_CHANGED"
+
+// Event is a notification about a payment. ID identifies the event, not the
+// payment: retries of the same notification share it.
+type Event struct {
+	ID        string                        `json:"id"`
+	Type      string                        `json:"type"`
+	CreatedAt utils.Timestamp               `json:"createdAt"`
+	Data      payments.PaymentStatusPayload `json:"data"`
+}
+
+func (e *Event) Status() payments.PaymentInitiationStatus {
+	return payments.PaymentInitiationStatus(e.Data.Status)
+}
+
+// HandlerFunc processes an event. Returning an error makes the Receiver
+// answer with a non-2xx status, so the sender retries the notification.
+type HandlerFunc func(ctx context.Context, event *Event) error
+
+type ClaimResult int
+
+const (
+	// Claimed means the event must be processed by the caller.
+	Claimed ClaimResult = iota
+	// Processing means another delivery of the event is being processed.
+	Processing
+	// Processed means the event was already processed.
+	Processed
+)
+
+// EventStore deduplicates events by ID across retries and concurrent
+// deliveries.
+type EventStore interface {
+	// Claim reserves an event for processing, unless it was already
+	// processed or is being processed.
+	Claim(id string) (ClaimResult, error)
+	// Complete marks a claimed event as processed.
+	Complete(id string) error
+	// Release frees the claim of an event whose processing failed, so its
+	// retry is processed again.
+	Release(id string) error
+}
+
+// MemoryEventStore is an EventStore for a single process that forgets
+// processed events after TTL.
+type MemoryEventStore struct {
+	TTL time.Duration
+
+	mutex     sync.Mutex
+	claimed   map[string]bool
+	processed map[string]time.Time
+}
+
+func NewMemoryEventStore(ttl time.Duration) *MemoryEventStore {
+	return &MemoryEventStore{TTL: ttl, claimed: make(map[string]bool), processed: make(map[string]time.Time)}
+}
+
+func (s *MemoryEventStore) Claim(id string) (ClaimResult, error) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	now := time.Now()
+	for processedID, processedAt := range s.processed {
+		if now.Sub(processedAt) > s.TTL {
+			delete(s.processed, processedID)
+		}
+	}
+
+	if _, ok := s.processed[id]; ok {
+		return Processed, nil
+	}
+	if s.claimed[id] {
+		return Processing, nil
+	}
+	s.claimed[id] = true
+	return Claimed, nil
+}
+
+func (s *MemoryEventStore) Complete(id string) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	delete(s.claimed, id)
+	s.processed[id] = time.Now()
+	return nil
+}
+
+func (s *MemoryEventStore) Release(id string) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	delete(s.claimed, id)
+	return nil
+}
+
+// Receiver is an http.Handler for payment notifications. It verifies each
+// notification, skips events already processed and calls the handlers of the
+// event status, answering:
+//
+//   - 200 when the event was processed, now or before;
+//   - 400 for malformed events and 401 for unauthentic ones, which are not
+//     worth retrying;
+//   - 409 while the same event is being processed by another delivery;
+//   - 500 when a handler failed, so the sender retries.
+type Receiver struct {
+	Verifier Verifier
+	Store    EventStore
+	// OnError, when set, is called with the errors of rejected notifications
+	// and failed handlers, e.g. for logging.
+	OnError func(event *Event, err error)
+
+	mutex    sync.RWMutex
+	handlers map[payments.PaymentInitiationStatus][]HandlerFunc
+	any      []HandlerFunc
+}
+
+// NewReceiver creates a Receiver deduplicating events in a MemoryEventStore
+// for a day. Replace Store to share deduplication between processes.
+func NewReceiver(verifier Verifier) *Receiver {
+	return &Receiver{
+		Verifier: verifier,
+		Store:    NewMemoryEventStore(24 * time.Hour),
+		handlers: make(map[payments.PaymentInitiationStatus][]HandlerFunc),
+	}
+}
+
+// On registers handler for the events of the given statuses.
+func (r *Receiver) On(handler HandlerFunc, statuses ...payments.PaymentInitiationStatus) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	for _, status := range statuses {
+		r.handlers[status] = append(r.handlers[status], handler)
+	}
+}
+
+// OnAny registers handler for every event, after the status handlers.
+func (r *Receiver) OnAny(handler HandlerFunc) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	r.any = append(r.any, handler)
+}
+
+func (r *Receiver) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	if req.Method != http.MethodPost {
+		w.Header().Set("Allow", http.MethodPost)
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	body, err := ioutil.ReadAll(http.MaxBytesReader(w, req.Body, MaxBodySize))
+	if err != nil {
+		r.reject(w, nil, http.StatusBadRequest, fmt.Errorf("failed to read the notification: %v", err))
+		return
+	}
+	payload, err := r.Verifier.Verify(req, body)
+	if err != nil {
+		r.reject(w, nil, http.StatusUnauthorized, err)
+		return
+	}
+
+	var event Event
+	err = json.Unmarshal(payload, &event)
+	if err != nil {
+		r.reject(w, nil, http.StatusBadRequest, fmt.Errorf("failed to decode the event: %v", err))
+		return
+	}
+	if event.ID == "" || event.Data.ID == "" {
+		r.reject(w, &event, http.StatusBadRequest, fmt.Errorf("event id and data.id are required"))
+		return
+	}
+	if event.Type != TestEvent {
+		payments.ObserveStatus(&event.Data)
+	}
+
+	claim, err := r.Store.Claim(event.ID)
+	if err != nil {
+		r.reject(w, &event, http.StatusInternalServerError, err)
+		return
+	}
+	switch claim {
+	case Processed:
+		w.WriteHeader(http.StatusOK)
+		return
+	case Processing:
+		r.reject(w, &event, http.StatusConflict, fmt.Errorf("event %s is being processed", event.ID))
+		return
+	}
+
+	err = r.dispatch(req.Context(), &event)
+	if err != nil {
+		_ = r.Store.Release(event.ID)
+		r.reject(w, &event, http.StatusInternalServerError, err)
+		return
+	}
+	err = r.Store.Complete(event.ID)
+	if err != nil {
+		r.reject(w, &event, http.StatusInternalServerError, err)
+		return
+	}
+	w.WriteHeader(http.StatusOK)
+}
+
+// dispatch calls the handlers of the event. A panicking handler is reported
+// as an error, so that the claim is released and the event retried.
+func (r *Receiver) dispatch(ctx context.Context, event *Event) (err error) {
+	defer func() {
+		if recovered := recover(); recovered != nil {
+			err = fmt.Errorf("handler of event %s panicked: %v", event.ID, recovered)
+		}
+	}()
+
+	r.mutex.RLock()
+	handlers := append(append([]HandlerFunc{}, r.handlers[event.Status()]...), r.any...)
+	r.mutex.RUnlock()
+
+	for _, handler := range handlers {
+		err = handler(ctx, event)
+		if err != nil {
+			return fmt.Errorf("handler of event %s failed: %v", event.ID, err)
+		}
+	}
+	return nil
+}
+
+func (r *Receiver) reject(w http.ResponseWriter, event *Event, status int, err error) {
+	if r.OnError != nil {
+		r.OnError(event, err)
+	}
+	http.Error(w, http.StatusText(status), status)
+}
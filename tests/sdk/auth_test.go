@@ -7,6 +7,7 @@ import (
 	"testing"
 
 	"iniciador-sdk/iniciador/auth"
+	"iniciador-sdk/iniciador/utils"
 	"iniciador-sdk/tests/sdk/helpers"
 )
 
@@ -147,3 +148,71 @@ func TestAuthClient_AuthInterface(t *testing.T) {
 		t.Errorf("expected result: %+v, actual result: %+v", expectedOutput, authInterfaceOutput)
 	}
 }
+
+func TestAuthClient_AuthInterfaceSession(t *testing.T) {
+	accessToken := helpers.NewAccessToken("testPaymentID")
+
+	// Create a test server
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		// Decode the request body
+		var requestBody map[string]interface{}
+		err := json.NewDecoder(r.Body).Decode(&requestBody)
+		if err != nil {
+			t.Errorf("failed to decode the request body: %v", err)
+		}
+
+		// Verify the prefilled payment next to the credentials
+		expectedBody := map[string]interface{}{
+			"clientId":       "testClientID",
+			"clientSecret":   "testClientSecret",
+			"amount":         1500,
+			"externalId":     "order-42",
+			"description":    "Order 42",
+			"paymentMethods": []string{"PIX_DICT"},
+			"redirectURL":    "https://example.com/success",
+			"metadata":       map[string]interface{}{"orderId": "42"},
+		}
+		if !helpers.IsEqual(requestBody, expectedBody) {
+			t.Errorf("expected request body %v, actual request body %v", expectedBody, requestBody)
+		}
+
+		// Send the simulated response
+		responseBody, _ := json.Marshal(auth.AuthInterfaceOutput{
+			AccessToken:  accessToken,
+			InterfaceURL: "testInterfaceURL",
+			PaymentID:    "testPaymentID",
+		})
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusOK)
+		_, _ = w.Write(responseBody)
+	}))
+	defer server.Close()
+
+	// Configure the authentication client for the test
+	authClient := auth.NewAuthClient("testClientID", "testClientSecret", "dev")
+	authClient.Environment = server.URL
+
+	// Execute the method to be tested
+	output, err := authClient.AuthInterfaceWithSession(&auth.InterfaceSession{
+		Amount:         1500,
+		ExternalID:     "order-42",
+		Description:    "Order 42",
+		PaymentMethods: []utils.PaymentMethod{utils.PixDict},
+		RedirectURL:    "https://example.com/success",
+		Metadata:       map[string]interface{}{"orderId": "42"},
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	// Verify the token claims
+	if output.Claims == nil || output.Claims.Payload.ID != "testPaymentID" {
+		t.Errorf("expected claims for payment testPaymentID, got %+v", output.Claims)
+	}
+
+	// Verify that invalid sessions are not sent
+	_, err = authClient.AuthInterfaceWithSession(&auth.InterfaceSession{PaymentMethods: []utils.PaymentMethod{"PIX_BOLETO"}})
+	if err == nil {
+		t.Errorf("expected an error for an invalid payment method")
+	}
+}
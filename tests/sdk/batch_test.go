@@ -0,0 +1,147 @@
+package sdk
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+
+	"iniciador-sdk/iniciador/auth"
+	"iniciador-sdk/iniciador/batch"
+	"iniciador-sdk/iniciador/payments"
+	"iniciador-sdk/iniciador/utils"
+)
+
+func TestBatchSubmit(t *testing.T) {
+	// Count the requests received per idempotency key
+	var mutex sync.Mutex
+	requests := make(map[string]int)
+
+	// Create a test server
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		// Verify the idempotency key header
+		key := r.Header.Get("Idempotency-Key")
+		if key == "" {
+			t.Errorf("expected an Idempotency-Key header")
+		}
+		mutex.Lock()
+		requests[key]++
+		mutex.Unlock()
+
+		// Reject one of the payments
+		if key == "payment-2" {
+			w.Header().Set("Content-Type", "application/json")
+			w.WriteHeader(http.StatusUnprocessableEntity)
+			_, _ = w.Write([]byte(`{"errorCode":"INVALID_ACCOUNT","message":["invalid creditor account"],"statusCode":422}`))
+			return
+		}
+
+		// Create a simulated response
+		var payment payments.PaymentInitiationPayload
+		err := json.NewDecoder(r.Body).Decode(&payment)
+		if err != nil {
+			t.Errorf("failed to decode the request body: %v", err)
+		}
+		payment.ID = "id-" + key
+		responseBody, err := json.Marshal(payment)
+		if err != nil {
+			t.Errorf("failed to encode the response: %v", err)
+		}
+
+		// Send the simulated response
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusOK)
+		_, _ = w.Write(responseBody)
+	}))
+	defer server.Close()
+
+	// Configure the authentication client for the test
+	authClient := auth.NewAuthClient("testClientID", "testClientSecret", "dev")
+	authClient.Environment = server.URL
+
+	// Set up the batch items
+	newItems := func() []batch.Item {
+		return []batch.Item{
+			{Key: "payment-1", Payment: &payments.PaymentInitiationPayload{Amount: 1000, User: payments.User{TaxID: "52998224725"}}},
+			{Key: "payment-2", Payment: &payments.PaymentInitiationPayload{Amount: 2000, User: payments.User{TaxID: "52998224725"}}},
+			{Payment: &payments.PaymentInitiationPayload{ExternalID: "payment-3", Amount: 3000, User: payments.User{TaxID: "52998224725"}}},
+		}
+	}
+
+	// Verify that an invalid item stops the whole batch
+	items := newItems()
+	items[2].Payment.Amount = 0
+	report, err := batch.Submit(context.Background(), "testAccessToken", items, authClient, nil)
+	if err != batch.ErrInvalidBatch {
+		t.Fatalf("expected ErrInvalidBatch, got %v", err)
+	}
+	if len(report.Invalid()) != 1 || len(report.Skipped()) != 2 || len(requests) != 0 {
+		t.Errorf("expected 1 invalid and 2 skipped items without requests, got %+v", report.Items)
+	}
+
+	// Execute the batch, persisting results as they settle
+	var settled []batch.ItemResult
+	options := &batch.Options{
+		Workers:       2,
+		RatePerSecond: 100,
+		OnResult: func(result batch.ItemResult) {
+			settled = append(settled, result)
+		},
+	}
+	report, err = batch.Submit(context.Background(), "testAccessToken", newItems(), authClient, options)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	// Verify the report
+	if len(settled) != 3 {
+		t.Errorf("expected 3 settled results, got %d", len(settled))
+	}
+	if len(report.Created()) != 2 || len(report.Rejected()) != 1 {
+		t.Fatalf("expected 2 created and 1 rejected items, got %+v", report.Items)
+	}
+	rejected := report.Items[1]
+	if rejected.Key != "payment-2" || rejected.APIError == nil || rejected.APIError.StatusCode != http.StatusUnprocessableEntity {
+		t.Errorf("expected payment-2 to be rejected with an APIError, got %+v", rejected)
+	}
+	if report.Items[2].Payment == nil || report.Items[2].Payment.ID != "id-payment-3" {
+		t.Errorf("expected payment-3 to be created, got %+v", report.Items[2])
+	}
+
+	// Resume from a stored report, only sending what was not created
+	var stored batch.Report
+	storedBytes, _ := json.Marshal(report)
+	_ = json.Unmarshal(storedBytes, &stored)
+	options = &batch.Options{Previous: &stored}
+	report, err = batch.Submit(context.Background(), "testAccessToken", newItems(), authClient, options)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if requests["payment-1"] != 1 || requests["payment-2"] != 2 || requests["payment-3"] != 1 {
+		t.Errorf("expected only payment-2 to be sent again, got %v", requests)
+	}
+	if len(report.Created()) != 2 {
+		t.Errorf("expected created items to be kept from the previous report, got %+v", report.Items)
+	}
+
+	// Verify that a cancelled context skips the remaining items
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	report, err = batch.Submit(ctx, "testAccessToken", newItems(), authClient, nil)
+	if err != context.Canceled || len(report.Skipped()) != 3 {
+		t.Errorf("expected all items to be skipped after cancellation, got %v %+v", err, report.Items)
+	}
+}
+
+func TestAPIError(t *testing.T) {
+	apiError := &utils.APIError{
+		StatusCode: http.StatusBadRequest,
+		Response:   utils.Error{StatusCode: http.StatusBadRequest, Message: []string{"amount must be positive"}},
+	}
+	expected := "request failed with status code 400: amount must be positive"
+	if apiError.Error() != expected {
+		t.Errorf("expected error %q, actual error %q", expected, apiError.Error())
+	}
+}
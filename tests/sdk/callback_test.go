@@ -0,0 +1,183 @@
+package sdk
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"iniciador-sdk/iniciador/auth"
+	"iniciador-sdk/iniciador/callback"
+	"iniciador-sdk/iniciador/payments"
+	"iniciador-sdk/tests/sdk/helpers"
+)
+
+func TestCallbackHandler(t *testing.T) {
+	paymentStatus := string(payments.PaymentCompleted)
+
+	// Create a test server answering the payment status
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		responseBody, _ := json.Marshal(payments.PaymentStatusPayload{ID: "testPaymentID", Status: paymentStatus})
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusOK)
+		_, _ = w.Write(responseBody)
+	}))
+	defer server.Close()
+
+	// Configure the authentication client for the test
+	authClient := auth.NewAuthClient("testClientID", "testClientSecret", "dev")
+	authClient.Environment = server.URL
+
+	// Set up the handler, recording the outcome
+	var result *callback.Result
+	var failure *callback.Failure
+	store := callback.NewMemoryStore()
+	handler := &callback.Handler{
+		AuthClient: authClient,
+		Store:      store,
+		OnSuccess: func(w http.ResponseWriter, r *http.Request, res *callback.Result) {
+			result = res
+			w.WriteHeader(http.StatusOK)
+		},
+		OnFailure: func(w http.ResponseWriter, r *http.Request, f *callback.Failure) {
+			failure = f
+			w.WriteHeader(http.StatusBadRequest)
+		},
+	}
+
+	output := &auth.AuthInterfaceOutput{AccessToken: helpers.NewAccessToken("testPaymentID"), PaymentID: "testPaymentID"}
+	redirect := func(query string) {
+		result, failure = nil, nil
+		handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/callback?"+query, nil))
+	}
+
+	// Verify the state is added to the redirect URL
+	session, err := callback.NewSession("testExternalID", 0)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	redirectURL, err := callback.WithState("https://example.com/done?order=42", session.State)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if redirectURL != "https://example.com/done?order=42&state="+session.State {
+		t.Errorf("unexpected redirect URL %s", redirectURL)
+	}
+	err = session.Save(store, output)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	// Verify a completed payment
+	redirect("state=" + session.State + "&paymentId=testPaymentID&externalId=testExternalID")
+	if failure != nil {
+		t.Fatalf("unexpected failure: %v", failure.Err)
+	}
+	if result == nil || result.Status.ID != "testPaymentID" || result.Session.ExternalID != "testExternalID" {
+		t.Errorf("unexpected result %+v", result)
+	}
+
+	// Verify that the same redirect cannot be replayed
+	redirect("state=" + session.State + "&paymentId=testPaymentID")
+	if failure == nil || !errors.Is(failure.Err, callback.ErrUnknownState) {
+		t.Errorf("expected ErrUnknownState on replay, got %+v", failure)
+	}
+
+	// Verify a redirect for another payment
+	session, _ = callback.NewSession("", 0)
+	_ = session.Save(store, output)
+	redirect("state=" + session.State + "&paymentId=otherPaymentID")
+	if failure == nil || !errors.Is(failure.Err, callback.ErrTampered) {
+		t.Errorf("expected ErrTampered, got %+v", failure)
+	}
+
+	// Verify that a tampered redirect does not consume the session
+	redirect("state=" + session.State + "&paymentId=testPaymentID")
+	if failure != nil || result == nil {
+		t.Errorf("expected the legitimate redirect to succeed, got %+v", failure)
+	}
+
+	// Verify an expired session
+	session, _ = callback.NewSession("", time.Nanosecond)
+	_ = session.Save(store, output)
+	time.Sleep(time.Millisecond)
+	redirect("state=" + session.State)
+	if failure == nil || !errors.Is(failure.Err, callback.ErrSessionExpired) {
+		t.Errorf("expected ErrSessionExpired, got %+v", failure)
+	}
+
+	// Verify that saving a session drops the expired ones
+	expired, _ := callback.NewSession("", time.Nanosecond)
+	_ = expired.Save(store, output)
+	time.Sleep(time.Millisecond)
+	session, _ = callback.NewSession("", 0)
+	_ = session.Save(store, output)
+	if _, err := store.Get(expired.State); !errors.Is(err, callback.ErrUnknownState) {
+		t.Errorf("expected the expired session to be dropped, got %v", err)
+	}
+
+	// Verify a rejected payment
+	paymentStatus = string(payments.PaymentRejected)
+	session, _ = callback.NewSession("", 0)
+	_ = session.Save(store, output)
+	redirect("state=" + session.State)
+	if failure == nil || failure.Status == nil || failure.Status.Status != string(payments.PaymentRejected) {
+		t.Errorf("expected a failure with the rejected status, got %+v", failure)
+	}
+
+	// Verify a payment that is still pending
+	paymentStatus = string(payments.PaymentPending)
+	session, _ = callback.NewSession("", 0)
+	_ = session.Save(store, output)
+	redirect("state=" + session.State)
+	if failure == nil || !errors.Is(failure.Err, callback.ErrPaymentPending) {
+		t.Errorf("expected ErrPaymentPending, got %+v", failure)
+	}
+}
+
+func TestCallbackHandlerDisconnect(t *testing.T) {
+	// Create a test server answering a pending payment
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		responseBody, _ := json.Marshal(payments.PaymentStatusPayload{ID: "testPaymentID", Status: string(payments.PaymentPending)})
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusOK)
+		_, _ = w.Write(responseBody)
+	}))
+	defer server.Close()
+
+	// Configure the authentication client for the test
+	authClient := auth.NewAuthClient("testClientID", "testClientSecret", "dev")
+	authClient.Environment = server.URL
+
+	var failure *callback.Failure
+	store := callback.NewMemoryStore()
+	handler := &callback.Handler{
+		AuthClient:   authClient,
+		Store:        store,
+		PollInterval: time.Second,
+		PollTimeout:  time.Minute,
+		OnFailure: func(w http.ResponseWriter, r *http.Request, f *callback.Failure) {
+			failure = f
+		},
+	}
+
+	session, _ := callback.NewSession("", 0)
+	_ = session.Save(store, &auth.AuthInterfaceOutput{AccessToken: helpers.NewAccessToken("testPaymentID"), PaymentID: "testPaymentID"})
+
+	// Polling stops when the client disconnects
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	req := httptest.NewRequest(http.MethodGet, "/callback?state="+session.State, nil).WithContext(ctx)
+	start := time.Now()
+	handler.ServeHTTP(httptest.NewRecorder(), req)
+
+	if elapsed := time.Since(start); elapsed > 5*time.Second {
+		t.Errorf("expected polling to stop with the request, took %s", elapsed)
+	}
+	if failure == nil || !errors.Is(failure.Err, context.DeadlineExceeded) {
+		t.Errorf("expected context.DeadlineExceeded, got %+v", failure)
+	}
+}
@@ -0,0 +1,120 @@
+package sdk
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"iniciador-sdk/iniciador/auth"
+	"iniciador-sdk/iniciador/consents"
+	"iniciador-sdk/iniciador/payments"
+)
+
+func TestConsents(t *testing.T) {
+	consent := consents.ConsentPayload{
+		ID:         "testConsentID",
+		Status:     consents.Authorized,
+		PaymentID:  "testPaymentID",
+		CustomerID: "testCustomerID",
+		Amount:     1500,
+		Creditor:   &payments.BankAccount{Name: "Test Creditor", ISPB: "12345678"},
+	}
+
+	// Create a test server
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var response interface{}
+
+		// Verify the request method and path
+		switch {
+		case r.Method == http.MethodGet && r.URL.Path == "/consents/testConsentID":
+			response = consent
+		case r.Method == http.MethodPatch && r.URL.Path == "/consents/testConsentID":
+			var requestBody map[string]interface{}
+			err := json.NewDecoder(r.Body).Decode(&requestBody)
+			if err != nil {
+				t.Errorf("failed to decode the request body: %v", err)
+			}
+			if requestBody["status"] != string(consents.Revoked) || requestBody["revocationReason"] != "testReason" {
+				t.Errorf("unexpected revoke request body %v", requestBody)
+			}
+			consent.Status = consents.Revoked
+			consent.RevocationReason = "testReason"
+			response = consent
+		case r.Method == http.MethodGet && r.URL.Path == "/consents":
+			if r.URL.Query().Get("customerId") != "testCustomerID" || r.URL.Query().Get("status") != string(consents.Revoked) {
+				t.Errorf("unexpected query parameters %v", r.URL.Query())
+			}
+			response = consents.ConsentsOutput{Data: []consents.ConsentPayload{consent}, Cursor: consents.Cursor{AfterCursor: "next"}}
+		default:
+			t.Errorf("unexpected request %s %s", r.Method, r.URL.Path)
+		}
+
+		responseBody, err := json.Marshal(response)
+		if err != nil {
+			t.Errorf("failed to encode the response: %v", err)
+		}
+
+		// Send the simulated response
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusOK)
+		_, _ = w.Write(responseBody)
+	}))
+	defer server.Close()
+
+	// Configure the authentication client for the test
+	authClient := auth.NewAuthClient("testClientID", "testClientSecret", "dev")
+	authClient.Environment = server.URL
+
+	// Fetch the consent of a payment
+	fetched, err := consents.GetForPayment("testAccessToken", &payments.PaymentStatusPayload{ID: "testPaymentID", ConsentID: "testConsentID"}, authClient)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if fetched.Status != consents.Authorized || fetched.Amount != 1500 || fetched.Creditor.ISPB != "12345678" {
+		t.Errorf("unexpected consent %+v", fetched)
+	}
+
+	// Revoke the consent
+	revoked, err := consents.Revoke("testAccessToken", "testConsentID", "testReason", authClient)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if revoked.Status != consents.Revoked {
+		t.Errorf("expected status %s, got %s", consents.Revoked, revoked.Status)
+	}
+
+	// Verify that a revoked consent cannot be revoked again
+	_, err = consents.Revoke("testAccessToken", "testConsentID", "testReason", authClient)
+	if err == nil {
+		t.Errorf("expected an error revoking a revoked consent")
+	}
+
+	// List the consents of the customer
+	output, err := consents.List("testAccessToken", &consents.ConsentsFilter{CustomerID: "testCustomerID", Status: consents.Revoked}, authClient)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(output.Data) != 1 || output.Cursor.AfterCursor != "next" {
+		t.Errorf("unexpected output %+v", output)
+	}
+}
+
+func TestConsentIsExpired(t *testing.T) {
+	now := time.Date(2024, time.March, 1, 12, 0, 0, 0, time.UTC)
+	consent := consents.ConsentPayload{Status: consents.AwaitingAuthorization}
+	if consent.IsExpired(now) {
+		t.Errorf("expected a consent without expiration not to expire")
+	}
+
+	consent.ExpirationDateTime.Time = now.Add(-time.Minute)
+	if !consent.IsExpired(now) {
+		t.Errorf("expected the consent to be expired")
+	}
+
+	consent.Status = consents.Consumed
+	if consent.IsExpired(now) {
+		t.Errorf("expected a consumed consent not to expire")
+	}
+}
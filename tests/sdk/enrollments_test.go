@@ -0,0 +1,153 @@
+package sdk
+
+import (
+	"testing"
+
+	"iniciador-sdk/iniciador/auth"
+	"iniciador-sdk/iniciador/enrollments"
+	"iniciador-sdk/iniciador/enrollments/enrollmentstest"
+	"iniciador-sdk/iniciador/payments"
+)
+
+func TestEnrollmentFlow(t *testing.T) {
+	const origin = "https://app.example.com"
+
+	// Start the stub server
+	server := enrollmentstest.NewServer(origin)
+	defer server.Close()
+
+	// Configure the authentication client for the test
+	authClient := auth.NewAuthClient("testClientID", "testClientSecret", "dev")
+	authClient.Environment = server.URL
+
+	authenticator, err := enrollmentstest.NewAuthenticator(origin)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	riskSignals := &enrollments.RiskSignals{DeviceID: "testDeviceID", OSVersion: "14", Language: "pt-BR"}
+
+	// Create the enrollment and send the risk signals
+	enrollment, err := enrollments.Create("testAccessToken", &enrollments.EnrollmentPayload{
+		User:             payments.User{TaxID: "52998224725", Name: "Test User"},
+		TransactionLimit: 50000,
+	}, authClient)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if enrollment.Status != enrollments.AwaitingRiskSignals {
+		t.Fatalf("expected status %s, got %s", enrollments.AwaitingRiskSignals, enrollment.Status)
+	}
+	enrollment, err = enrollments.SendRiskSignals("testAccessToken", enrollment.ID, riskSignals, authClient)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if enrollment.Status != enrollments.AwaitingAccountHolderValidation || enrollment.RedirectEnrollmentURL == "" {
+		t.Fatalf("expected the account holder validation redirect, got %+v", enrollment)
+	}
+
+	// Verify that registration requires the account holder validation
+	_, err = enrollments.GetRegistrationOptions("testAccessToken", enrollment.ID, &enrollments.RegistrationOptionsInput{RP: "example.com", Platform: enrollments.Android}, authClient)
+	if err == nil {
+		t.Errorf("expected an error before the account holder validation")
+	}
+	err = server.ValidateAccountHolder(enrollment.ID)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	// Register the FIDO credential
+	registrationOptions, err := enrollments.GetRegistrationOptions("testAccessToken", enrollment.ID, &enrollments.RegistrationOptionsInput{RP: "example.com", Platform: enrollments.Android}, authClient)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	registration, err := authenticator.Register(registrationOptions)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	enrollment, err = enrollments.Register("testAccessToken", enrollment.ID, registration, authClient)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if enrollment.Status != enrollments.Authorized {
+		t.Fatalf("expected status %s, got %s", enrollments.Authorized, enrollment.Status)
+	}
+
+	// Verify that payments above the transaction limit are refused
+	payment := &payments.PaymentInitiationPayload{
+		Method: payments.PixDict,
+		PixKey: "john@example.com",
+		Amount: 60000,
+		User:   payments.User{TaxID: "52998224725"},
+	}
+	_, err = enrollments.SendPayment("testAccessToken", enrollment, payment, authClient)
+	if err == nil {
+		t.Errorf("expected an error for an amount above the transaction limit")
+	}
+
+	// Create and authorize a payment
+	payment.Amount = 1500
+	created, err := enrollments.SendPayment("testAccessToken", enrollment, payment, authClient)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if *created.Status != payments.ConsentAwaitingAuthorization {
+		t.Errorf("expected status %s, got %s", payments.ConsentAwaitingAuthorization, *created.Status)
+	}
+	signOptions, err := enrollments.GetSignOptions("testAccessToken", enrollment.ID, &enrollments.SignOptionsInput{RP: "example.com", Platform: enrollments.Android, PaymentID: created.ID}, authClient)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	assertion, err := authenticator.Sign(signOptions)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	authorized, err := enrollments.AuthorizePayment("testAccessToken", enrollment.ID, created.ID, &enrollments.AuthorizationInput{FidoAssertion: *assertion, RiskSignals: *riskSignals}, authClient)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if *authorized.Status != payments.PaymentCompleted {
+		t.Errorf("expected status %s, got %s", payments.PaymentCompleted, *authorized.Status)
+	}
+
+	// Verify that a replayed assertion is refused
+	_, err = enrollments.AuthorizePayment("testAccessToken", enrollment.ID, created.ID, &enrollments.AuthorizationInput{FidoAssertion: *assertion, RiskSignals: *riskSignals}, authClient)
+	if err == nil {
+		t.Errorf("expected an error for a replayed assertion")
+	}
+
+	// Revoke the enrollment
+	enrollment, err = enrollments.Revoke("testAccessToken", enrollment.ID, "device lost", authClient)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if enrollment.Status != enrollments.Revoked {
+		t.Errorf("expected status %s, got %s", enrollments.Revoked, enrollment.Status)
+	}
+	fetched, err := enrollments.Get("testAccessToken", enrollment.ID, authClient)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if fetched.Status != enrollments.Revoked {
+		t.Errorf("expected status %s, got %s", enrollments.Revoked, fetched.Status)
+	}
+}
+
+func TestEnrollmentRootedDevice(t *testing.T) {
+	server := enrollmentstest.NewServer("https://app.example.com")
+	defer server.Close()
+
+	authClient := auth.NewAuthClient("testClientID", "testClientSecret", "dev")
+	authClient.Environment = server.URL
+
+	enrollment, err := enrollments.Create("testAccessToken", &enrollments.EnrollmentPayload{User: payments.User{TaxID: "52998224725"}}, authClient)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	enrollment, err = enrollments.SendRiskSignals("testAccessToken", enrollment.ID, &enrollments.RiskSignals{DeviceID: "testDeviceID", IsRootedDevice: true}, authClient)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if enrollment.Status != enrollments.Rejected || enrollment.RejectionReason == nil {
+		t.Errorf("expected a rejected enrollment, got %+v", enrollment)
+	}
+}
@@ -0,0 +1,134 @@
+package sdk
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+
+	"iniciador-sdk/iniciador/exports"
+	"iniciador-sdk/iniciador/payments"
+	"iniciador-sdk/iniciador/utils"
+)
+
+func exportRecords() []*exports.Record {
+	completed := payments.PaymentInitiationStatus(payments.PaymentCompleted)
+	return []*exports.Record{
+		exports.FromPayment(&payments.PaymentInitiationPayload{
+			ID:          "payment1",
+			ExternalID:  "order-1",
+			EndToEndID:  "E12345678202402292330abcdefghijk",
+			Status:      &completed,
+			Method:      payments.PixDict,
+			Amount:      0.1*100 + 0.2*100,
+			CreatedAt:   utils.NewTimestamp(time.Date(2024, time.March, 1, 2, 30, 0, 0, time.UTC)),
+			Description: "Pedido 1, \"especial\"",
+			User:        payments.User{Name: "João", TaxID: "52998224725"},
+			Metadata:    payments.Metadata{"store": "SP-01"},
+		}),
+		exports.FromStatus(&payments.PaymentStatusPayload{
+			ID:        "payment2",
+			Status:    string(payments.PaymentPending),
+			Amount:    123456,
+			CreatedAt: utils.NewTimestamp(time.Date(2024, time.March, 1, 12, 0, 0, 0, time.UTC)),
+		}),
+	}
+}
+
+func TestCSVExport(t *testing.T) {
+	var buffer bytes.Buffer
+	writer := exports.NewCSVWriter(&buffer, exports.ColumnID, exports.ColumnAmount, exports.ColumnCreatedAt, exports.ColumnDescription, exports.MetadataColumn("store"))
+	writer.Comma = ';'
+
+	err := exports.WriteAll(writer, exportRecords())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	expected := "id;amount;createdAt;description;store\n" +
+		"payment1;0.30;2024-02-29T23:30:00-03:00;\"Pedido 1, \"\"especial\"\"\";SP-01\n" +
+		"payment2;1234.56;2024-03-01T09:00:00-03:00;;\n"
+	if buffer.String() != expected {
+		t.Errorf("expected CSV:\n%s\nactual CSV:\n%s", expected, buffer.String())
+	}
+}
+
+func TestCSVExportFormulas(t *testing.T) {
+	var buffer bytes.Buffer
+	writer := exports.NewCSVWriter(&buffer, exports.ColumnAmount, exports.ColumnPayerName, exports.ColumnDescription)
+
+	records := []*exports.Record{
+		{Amount: -1050, PayerName: "=HYPERLINK(\"http://example.com\")", Description: "+55 11 99999-9999"},
+		{Amount: 100, PayerName: "@SUM(A1:A2)", Description: "-2+3"},
+		{Amount: 100, PayerName: "\tTab", Description: "João = Maria"},
+	}
+	err := exports.WriteAll(writer, records)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	// Text cells are escaped, amounts are kept numeric
+	expected := "amount,payerName,description\n" +
+		"-10.50,\"'=HYPERLINK(\"\"http://example.com\"\")\",'+55 11 99999-9999\n" +
+		"1.00,'@SUM(A1:A2),'-2+3\n" +
+		"1.00,'\tTab,João = Maria\n"
+	if buffer.String() != expected {
+		t.Errorf("expected CSV:\n%s\nactual CSV:\n%s", expected, buffer.String())
+	}
+}
+
+func TestJSONLinesExport(t *testing.T) {
+	var buffer bytes.Buffer
+	err := exports.WriteAll(exports.NewJSONLinesWriter(&buffer), exportRecords())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	lines := strings.Split(strings.TrimSpace(buffer.String()), "\n")
+	if len(lines) != 2 {
+		t.Fatalf("expected 2 lines, got %d:\n%s", len(lines), buffer.String())
+	}
+	if !strings.Contains(lines[1], `"amount":1234.56,"amountCents":123456`) {
+		t.Errorf("expected an exact amount, got %s", lines[1])
+	}
+
+	var first map[string]interface{}
+	err = json.Unmarshal([]byte(lines[0]), &first)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if first["createdAt"] != "2024-02-29T23:30:00-03:00" || first["amount"] != 0.3 {
+		t.Errorf("unexpected record %v", first)
+	}
+}
+
+func TestOFXExport(t *testing.T) {
+	var buffer bytes.Buffer
+	writer := exports.NewOFXWriter(&buffer, exports.OFXStatement{
+		BankID:    "12345678",
+		BranchID:  "0001",
+		AccountID: "123456",
+		Start:     time.Date(2024, time.February, 29, 3, 0, 0, 0, time.UTC),
+		End:       time.Date(2024, time.March, 1, 3, 0, 0, 0, time.UTC),
+	})
+
+	err := exports.WriteAll(writer, exportRecords())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	ofx := buffer.String()
+	for _, expected := range []string{
+		"<DTSTART>20240229000000[-3:BRT]</DTSTART><DTEND>20240301000000[-3:BRT]</DTEND>",
+		"<TRNTYPE>CREDIT</TRNTYPE><DTPOSTED>20240229233000[-3:BRT]</DTPOSTED><TRNAMT>0.30</TRNAMT><FITID>E12345678202402292330abcdefghijk</FITID>",
+		"<BALAMT>0.30</BALAMT>",
+	} {
+		if !strings.Contains(ofx, expected) {
+			t.Errorf("expected OFX to contain %s, got:\n%s", expected, ofx)
+		}
+	}
+	if strings.Contains(ofx, "payment2") {
+		t.Errorf("expected pending payments to be skipped, got:\n%s", ofx)
+	}
+}
@@ -1,6 +1,7 @@
 package helpers
 
 import (
+	"encoding/base64"
 	"encoding/json"
 	"net/url"
 	"reflect"
@@ -23,3 +24,12 @@ func IsEqual(a, b interface{}) bool {
 func AreURLQueryParamsEqual(params1, params2 url.Values) bool {
 	return reflect.DeepEqual(params1, params2)
 }
+
+// NewAccessToken builds an unsigned JWT carrying the given payment ID in its payload
+func NewAccessToken(paymentID string) string {
+	header := base64.RawURLEncoding.EncodeToString([]byte(`{"alg":"HS256","typ":"JWT"}`))
+	payload, _ := json.Marshal(map[string]interface{}{
+		"payload": map[string]interface{}{"id": paymentID},
+	})
+	return header + "." + base64.RawURLEncoding.EncodeToString(payload) + ".signature"
+}
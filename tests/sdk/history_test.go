@@ -0,0 +1,119 @@
+package sdk
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"iniciador-sdk/iniciador/auth"
+	"iniciador-sdk/iniciador/history"
+	"iniciador-sdk/iniciador/payments"
+	"iniciador-sdk/iniciador/webhooks"
+	"iniciador-sdk/tests/sdk/helpers"
+)
+
+func TestPaymentHistory(t *testing.T) {
+	// Create a test server reporting the payment status changes
+	responses := []string{
+		`{"id":"payment-1","externalId":"order-1","status":"ENQUEUED","updatedAt":"2024-02-29T10:00:00Z"}`,
+		`{"id":"payment-1","externalId":"order-1","status":"ENQUEUED","updatedAt":"2024-02-29T10:00:00Z"}`,
+		`{"id":"payment-1","externalId":"order-1","status":"CONSENT_AUTHORIZED","updatedAt":"2024-02-29T10:01:00Z"}`,
+	}
+	request := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusOK)
+		_, _ = w.Write([]byte(responses[request]))
+		request++
+	}))
+	defer server.Close()
+
+	// Configure the authentication client for the test
+	authClient := auth.NewAuthClient("testClientID", "testClientSecret", "dev")
+	authClient.Environment = server.URL
+
+	// Record every observed status
+	store := history.NewMemoryStore()
+	payments.EnableStatusObserver(history.Observer(store, nil))
+	defer payments.DisableStatusObserver()
+
+	// Observe the statuses by polling
+	for range responses {
+		_, err := payments.Status(helpers.NewAccessToken("payment-1"), authClient)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	}
+
+	// Observe the completion by webhook, then a late webhook of an earlier status
+	secret := []byte("testSecret")
+	receiver := webhooks.NewReceiver(&webhooks.HMACVerifier{Secret: secret})
+	notifications := []string{
+		`{"id":"event-2","type":"PAYMENT_STATUS_CHANGED","data":{"id":"payment-1","status":"PAYMENT_COMPLETED","endToEndId":"E12345678202402291002abcdefghijk","updatedAt":"2024-02-29T10:02:00Z"}}`,
+		`{"id":"event-1","type":"PAYMENT_STATUS_CHANGED","data":{"id":"payment-1","status":"CONSENT_AUTHORIZED","updatedAt":"2024-02-29T10:01:00Z"}}`,
+	}
+	for _, notification := range notifications {
+		recorder := httptest.NewRecorder()
+		receiver.ServeHTTP(recorder, signedNotification(secret, time.Now(), []byte(notification)))
+		if recorder.Code != http.StatusOK {
+			t.Fatalf("expected status 200, got %d", recorder.Code)
+		}
+	}
+
+	// Verify the timeline
+	timeline, err := store.Timeline("payment-1")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	var actual []string
+	for _, transition := range timeline.Transitions {
+		actual = append(actual, fmt.Sprintf("%s %s", transition.At.UTC().Format("15:04"), transition.Status))
+	}
+	expected := []string{"10:00 ENQUEUED", "10:01 CONSENT_AUTHORIZED", "10:02 PAYMENT_COMPLETED"}
+	if !helpers.IsEqual(actual, expected) {
+		t.Errorf("expected transitions %v, actual transitions %v", expected, actual)
+	}
+	if timeline.ExternalID != "order-1" || timeline.EndToEndID != "E12345678202402291002abcdefghijk" {
+		t.Errorf("unexpected timeline identifiers %+v", timeline)
+	}
+
+	_, err = store.Timeline("unknown")
+	if err != history.ErrNotFound {
+		t.Errorf("expected ErrNotFound, got %v", err)
+	}
+}
+
+func TestStuckPayments(t *testing.T) {
+	store := history.NewMemoryStore()
+	now := time.Date(2024, time.February, 29, 12, 0, 0, 0, time.UTC)
+
+	observe := func(id, status string, at time.Time) {
+		payload := &payments.PaymentStatusPayload{ID: id, Status: status}
+		payload.UpdatedAt.Time = at
+		err := store.Observe(payload, at)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	}
+	observe("recent", "PAYMENT_PENDING", now.Add(-10*time.Minute))
+	observe("old", "PAYMENT_PENDING", now.Add(-2*time.Hour))
+	observe("older", "PAYMENT_PENDING", now.Add(-3*time.Hour))
+	observe("completed", "PAYMENT_PENDING", now.Add(-5*time.Hour))
+	observe("completed", "PAYMENT_COMPLETED", now.Add(-4*time.Hour))
+
+	// Find the payments pending for over an hour
+	stuck, err := history.Stuck(store, payments.PaymentPending, time.Hour, now)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	var actual []string
+	for _, timeline := range stuck {
+		actual = append(actual, timeline.PaymentID)
+	}
+	expected := []string{"older", "old"}
+	if !helpers.IsEqual(actual, expected) {
+		t.Errorf("expected stuck payments %v, actual stuck payments %v", expected, actual)
+	}
+}
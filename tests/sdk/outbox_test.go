@@ -0,0 +1,335 @@
+package sdk
+
+import (
+	"context"
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"sync"
+	"testing"
+	"time"
+
+	"iniciador-sdk/iniciador/auth"
+	"iniciador-sdk/iniciador/outbox"
+	"iniciador-sdk/iniciador/payments"
+)
+
+// newOutboxServer returns a test server that initiates one payment per
+// Idempotency-Key, failing the first failures requests with a 500.
+func newOutboxServer(t *testing.T, failures int) (*httptest.Server, func() (int, map[string]int)) {
+	var mutex sync.Mutex
+	requests := 0
+	keys := make(map[string]int)
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mutex.Lock()
+		defer mutex.Unlock()
+		requests++
+
+		w.Header().Set("Content-Type", "application/json")
+		if requests <= failures {
+			w.WriteHeader(http.StatusInternalServerError)
+			_, _ = w.Write([]byte(`{"statusCode":500,"message":["unavailable"]}`))
+			return
+		}
+
+		var payment payments.PaymentInitiationPayload
+		err := json.NewDecoder(r.Body).Decode(&payment)
+		if err != nil {
+			t.Errorf("failed to decode the request body: %v", err)
+		}
+		if payment.Amount == 1 {
+			w.WriteHeader(http.StatusBadRequest)
+			_, _ = w.Write([]byte(`{"statusCode":400,"message":["amount below the minimum"]}`))
+			return
+		}
+
+		key := r.Header.Get("Idempotency-Key")
+		keys[key]++
+		status := payments.PaymentInitiationStatus(payments.Started)
+		_ = json.NewEncoder(w).Encode(payments.PaymentInitiationPayload{ID: "payment-" + key, Amount: payment.Amount, Status: &status})
+	}))
+
+	return server, func() (int, map[string]int) {
+		mutex.Lock()
+		defer mutex.Unlock()
+		return requests, keys
+	}
+}
+
+func newTestOutbox(storage outbox.Storage, server *httptest.Server) *outbox.Outbox {
+	// Configure the authentication client for the test
+	authClient := auth.NewAuthClient("testClientID", "testClientSecret", "dev")
+	authClient.Environment = server.URL
+
+	box := outbox.New(storage, authClient)
+	box.AccessToken = func() (string, error) { return "testAccessToken", nil }
+	box.Backoff = func(attempt int) time.Duration { return 0 }
+	return box
+}
+
+func TestOutboxRetry(t *testing.T) {
+	// Create a test server failing the first two requests
+	server, stats := newOutboxServer(t, 2)
+	defer server.Close()
+
+	var results []*outbox.Entry
+	box := newTestOutbox(outbox.NewMemoryStorage(), server)
+	box.OnResult = func(entry *outbox.Entry) { results = append(results, entry) }
+
+	payment := &payments.PaymentInitiationPayload{Amount: 100, User: payments.User{TaxID: "52998224725"}}
+	_, err := box.Enqueue("order-1", payment)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	// Enqueueing the same key again keeps the first entry
+	entry, err := box.Enqueue("order-1", &payments.PaymentInitiationPayload{Amount: 200, User: payments.User{TaxID: "52998224725"}})
+	if err != outbox.ErrDuplicateKey {
+		t.Fatalf("expected ErrDuplicateKey, got %v", err)
+	}
+	if entry.Payment.Amount != 100 {
+		t.Errorf("expected the existing entry, got %+v", entry)
+	}
+
+	// The first two attempts fail and are retried
+	for i := 0; i < 3; i++ {
+		err = box.ProcessPending(context.Background())
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	}
+
+	entry, err = box.Get("order-1")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if entry.Status != outbox.Sent || entry.Attempts != 3 || entry.Result == nil || entry.Result.ID != "payment-order-1" {
+		t.Errorf("expected the entry sent on the third attempt, got %+v", entry)
+	}
+	if len(results) != 1 || results[0].Key != "order-1" {
+		t.Errorf("expected one result, got %+v", results)
+	}
+
+	// Sent entries are not sent again
+	err = box.ProcessPending(context.Background())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	requests, keys := stats()
+	if requests != 3 || keys["order-1"] != 1 {
+		t.Errorf("expected 3 requests initiating one payment, got %d requests and %v", requests, keys)
+	}
+}
+
+func TestOutboxFailures(t *testing.T) {
+	// Create a test server that never succeeds
+	server, _ := newOutboxServer(t, 100)
+	defer server.Close()
+
+	box := newTestOutbox(outbox.NewMemoryStorage(), server)
+	box.MaxAttempts = 2
+
+	// Invalid payments are not enqueued
+	_, err := box.Enqueue("invalid", &payments.PaymentInitiationPayload{Amount: 100})
+	if _, ok := err.(payments.ValidationErrors); !ok {
+		t.Errorf("expected validation errors, got %v", err)
+	}
+
+	_, err = box.Enqueue("order-1", &payments.PaymentInitiationPayload{Amount: 100, User: payments.User{TaxID: "52998224725"}})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	for i := 0; i < 3; i++ {
+		_ = box.ProcessPending(context.Background())
+	}
+
+	// Server errors do not prove the payment was not initiated
+	entry, _ := box.Get("order-1")
+	if entry.Status != outbox.Unknown || entry.Attempts != 2 || entry.APIError == nil || entry.APIError.StatusCode != http.StatusInternalServerError {
+		t.Errorf("expected the entry unknown after 2 attempts, got %+v", entry)
+	}
+
+	// Gateway errors without a JSON body are classified the same way
+	gateway := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/html")
+		w.WriteHeader(http.StatusBadGateway)
+		_, _ = w.Write([]byte("<html><body>502 Bad Gateway</body></html>"))
+	}))
+	defer gateway.Close()
+
+	box = newTestOutbox(outbox.NewMemoryStorage(), gateway)
+	box.MaxAttempts = 1
+	_, err = box.Enqueue("order-2", &payments.PaymentInitiationPayload{Amount: 100, User: payments.User{TaxID: "52998224725"}})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	_ = box.ProcessPending(context.Background())
+
+	entry, _ = box.Get("order-2")
+	if entry.Status != outbox.Unknown || entry.APIError == nil || entry.APIError.StatusCode != http.StatusBadGateway {
+		t.Errorf("expected the entry unknown after a gateway error, got %+v", entry)
+	}
+}
+
+func TestOutboxPermanentFailure(t *testing.T) {
+	// Create a test server rejecting payments of one cent
+	server, stats := newOutboxServer(t, 0)
+	defer server.Close()
+
+	box := newTestOutbox(outbox.NewMemoryStorage(), server)
+	_, err := box.Enqueue("order-1", &payments.PaymentInitiationPayload{Amount: 1, User: payments.User{TaxID: "52998224725"}})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	_ = box.ProcessPending(context.Background())
+	_ = box.ProcessPending(context.Background())
+
+	entry, _ := box.Get("order-1")
+	if entry.Status != outbox.Failed || entry.Attempts != 1 || entry.APIError == nil || entry.APIError.StatusCode != http.StatusBadRequest {
+		t.Errorf("expected the entry failed without retries, got %+v", entry)
+	}
+	requests, _ := stats()
+	if requests != 1 {
+		t.Errorf("expected 1 request, got %d", requests)
+	}
+}
+
+func TestOutboxRecovery(t *testing.T) {
+	// Create a test server
+	server, stats := newOutboxServer(t, 0)
+	defer server.Close()
+
+	dir, err := ioutil.TempDir("", "outbox")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	defer os.RemoveAll(dir)
+
+	storage, err := outbox.NewFileStorage(dir)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	// Simulate a process that stopped while sending
+	now := time.Now()
+	err = storage.Insert(&outbox.Entry{
+		Key:       "order/1",
+		Payment:   &payments.PaymentInitiationPayload{Amount: 100, User: payments.User{TaxID: "52998224725"}},
+		Status:    outbox.Sending,
+		Attempts:  1,
+		CreatedAt: now,
+		UpdatedAt: now,
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	// Run a new outbox on the same directory until the entry is sent
+	storage, err = outbox.NewFileStorage(dir)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	box := newTestOutbox(storage, server)
+	box.PollInterval = 10 * time.Millisecond
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error)
+	box.OnResult = func(entry *outbox.Entry) { cancel() }
+	go func() { done <- box.Run(ctx) }()
+
+	select {
+	case err = <-done:
+		if err != context.Canceled {
+			t.Errorf("expected context.Canceled, got %v", err)
+		}
+	case <-time.After(5 * time.Second):
+		cancel()
+		t.Fatal("timed out waiting for the entry to be sent")
+	}
+
+	entry, err := storage.Get("order/1")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if entry.Status != outbox.Sent || entry.Attempts != 2 || entry.Result.ID != "payment-order/1" {
+		t.Errorf("expected the recovered entry sent, got %+v", entry)
+	}
+	_, keys := stats()
+	if keys["order/1"] != 1 {
+		t.Errorf("expected the payment sent with its key once, got %v", keys)
+	}
+}
+
+func TestOutboxUnknownOutcome(t *testing.T) {
+	// Create a test server and close it, so requests fail without an answer
+	server, stats := newOutboxServer(t, 0)
+	closed := httptest.NewServer(http.NotFoundHandler())
+	closed.Close()
+	defer server.Close()
+
+	var results []outbox.EntryStatus
+	box := newTestOutbox(outbox.NewMemoryStorage(), closed)
+	box.MaxAttempts = 2
+	box.OnResult = func(entry *outbox.Entry) { results = append(results, entry.Status) }
+
+	_, err := box.Enqueue("order-1", &payments.PaymentInitiationPayload{Amount: 100, User: payments.User{TaxID: "52998224725"}})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	for i := 0; i < 3; i++ {
+		_ = box.ProcessPending(context.Background())
+	}
+
+	// Without an answer the entry is not failed, as the payment may exist
+	entry, _ := box.Get("order-1")
+	if entry.Status != outbox.Unknown || entry.Attempts != 2 || entry.APIError != nil {
+		t.Errorf("expected the entry unknown after 2 attempts, got %+v", entry)
+	}
+
+	// Retrying sends it again under the same key
+	box.AuthClient.Environment = server.URL
+	err = box.Retry("order-1")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	_ = box.ProcessPending(context.Background())
+
+	entry, _ = box.Get("order-1")
+	if entry.Status != outbox.Sent || entry.Result.ID != "payment-order-1" {
+		t.Errorf("expected the retried entry sent, got %+v", entry)
+	}
+	_, keys := stats()
+	if keys["order-1"] != 1 {
+		t.Errorf("expected the payment sent with its key once, got %v", keys)
+	}
+	expectedResults := []outbox.EntryStatus{outbox.Unknown, outbox.Sent}
+	if len(results) != 2 || results[0] != expectedResults[0] || results[1] != expectedResults[1] {
+		t.Errorf("expected results %v, got %v", expectedResults, results)
+	}
+
+	// Sent entries cannot be retried
+	if box.Retry("order-1") == nil {
+		t.Errorf("expected an error retrying a sent entry")
+	}
+}
+
+func TestOutboxUnencodableEntry(t *testing.T) {
+	box := outbox.New(outbox.NewMemoryStorage(), auth.NewAuthClient("testClientID", "testClientSecret", "dev"))
+
+	// Metadata that cannot be encoded is an error, not a panic
+	payment := &payments.PaymentInitiationPayload{
+		Amount:   100,
+		User:     payments.User{TaxID: "52998224725"},
+		Metadata: payments.Metadata{"callback": func() {}},
+	}
+	_, err := box.Enqueue("order-1", payment)
+	if err == nil {
+		t.Fatal("expected an error enqueueing unencodable metadata")
+	}
+	if _, err = box.Get("order-1"); err != outbox.ErrNotFound {
+		t.Errorf("expected the entry not stored, got %v", err)
+	}
+}
@@ -1,7 +1,10 @@
 package sdk
 
 import (
+	"context"
 	"encoding/json"
+	"errors"
+	"fmt"
 	"net/http"
 	"net/http/httptest"
 	"net/url"
@@ -37,9 +40,9 @@ func TestGetParticipants(t *testing.T) {
 			"id":                []string{"testID"},
 			"name":              []string{"testName"},
 			"slug":              []string{"testSlug"},
-			"status":            []string{"testStatus"},
-			"firstParticipants": []string{"testFirstParticipants"},
-			"limit":             []string{"testLimit"},
+			"status":            []string{"ACTIVE"},
+			"firstParticipants": []string{"true"},
+			"limit":             []string{"10"},
 			"afterCursor":       []string{"testAfterCursor"},
 			"beforeCursor":      []string{"testBeforeCursor"},
 		}
@@ -61,6 +64,8 @@ func TestGetParticipants(t *testing.T) {
 					Slug:   "participant2",
 					Name:   "Participant 2",
 					Avatar: "avatar2",
+					Status: participants.ParticipantActive,
+					ISPB:   "12345678",
 				},
 			},
 			Cursor: participants.Cursor{
@@ -87,13 +92,14 @@ func TestGetParticipants(t *testing.T) {
 	authClient.Environment = server.URL
 
 	// Set up the filters
+	firstParticipants := true
 	filters := &participants.ParticipantsFilter{
 		ID:                "testID",
 		Name:              "testName",
 		Slug:              "testSlug",
-		Status:            "testStatus",
-		FirstParticipants: "testFirstParticipants",
-		Limit:             "testLimit",
+		Status:            participants.ParticipantActive,
+		FirstParticipants: &firstParticipants,
+		Limit:             10,
 		AfterCursor:       "testAfterCursor",
 		BeforeCursor:      "testBeforeCursor",
 	}
@@ -119,6 +125,8 @@ func TestGetParticipants(t *testing.T) {
 				Slug:   "participant2",
 				Name:   "Participant 2",
 				Avatar: "avatar2",
+				Status: participants.ParticipantActive,
+				ISPB:   "12345678",
 			},
 		},
 		Cursor: participants.Cursor{
@@ -130,3 +138,183 @@ func TestGetParticipants(t *testing.T) {
 		t.Errorf("expected result: %+v, actual result: %+v", expectedOutput, output)
 	}
 }
+
+func TestParticipantsFilterValidation(t *testing.T) {
+	// Create a test server that must not be reached
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		t.Errorf("unexpected request with query %s", r.URL.RawQuery)
+	}))
+	defer server.Close()
+
+	// Configure the authentication client for the test
+	authClient := auth.NewAuthClient("testClientID", "testClientSecret", "dev")
+	authClient.Environment = server.URL
+
+	invalidFilters := []*participants.ParticipantsFilter{
+		{Limit: -1},
+		{Limit: participants.MaxLimit + 1},
+		{Status: "UNKNOWN"},
+	}
+	for _, filters := range invalidFilters {
+		_, err := participants.GetParticipants("testAccessToken", filters, authClient)
+		if !errors.Is(err, participants.ErrInvalidFilter) {
+			t.Errorf("expected ErrInvalidFilter for %+v, got %v", filters, err)
+		}
+	}
+}
+
+func TestParticipantsFirstParticipants(t *testing.T) {
+	// Create a test server recording the query
+	var query url.Values
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		query = r.URL.Query()
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"data":[],"cursor":{}}`))
+	}))
+	defer server.Close()
+
+	// Configure the authentication client for the test
+	authClient := auth.NewAuthClient("testClientID", "testClientSecret", "dev")
+	authClient.Environment = server.URL
+
+	// An explicit false is sent
+	firstParticipants := false
+	_, err := participants.GetParticipants("testAccessToken", &participants.ParticipantsFilter{FirstParticipants: &firstParticipants}, authClient)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if values, ok := query["firstParticipants"]; !ok || values[0] != "false" {
+		t.Errorf("expected firstParticipants=false, got %v", query)
+	}
+
+	// An unset value is not
+	_, err = participants.GetParticipants("testAccessToken", &participants.ParticipantsFilter{}, authClient)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if _, ok := query["firstParticipants"]; ok {
+		t.Errorf("expected firstParticipants to be omitted, got %v", query)
+	}
+}
+
+// newParticipantsPagesServer returns a test server with three pages of two
+// participants, linked by cursors, and a counter of the requests.
+func newParticipantsPagesServer(t *testing.T) (*httptest.Server, *int) {
+	pages := map[string]participants.ParticipantFilterOutput{}
+	for page := 1; page <= 3; page++ {
+		output := participants.ParticipantFilterOutput{}
+		for i := 1; i <= 2; i++ {
+			id := fmt.Sprintf("participant%d", (page-1)*2+i)
+			output.Data = append(output.Data, participants.ParticipantsPayload{ID: id})
+		}
+		if page < 3 {
+			output.Cursor.AfterCursor = fmt.Sprintf("page%d", page+1)
+		}
+		if page > 1 {
+			output.Cursor.BeforeCursor = fmt.Sprintf("page%d", page-1)
+		}
+		pages[fmt.Sprintf("page%d", page)] = output
+	}
+
+	requests := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		cursor := r.URL.Query().Get("afterCursor")
+		if before := r.URL.Query().Get("beforeCursor"); before != "" {
+			cursor = before
+		}
+		if cursor == "" {
+			cursor = "page1"
+		}
+		output, ok := pages[cursor]
+		if !ok {
+			t.Errorf("unexpected cursor %s", cursor)
+		}
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode(output)
+	}))
+	return server, &requests
+}
+
+func TestParticipantsIterator(t *testing.T) {
+	// Create a test server
+	server, requests := newParticipantsPagesServer(t)
+	defer server.Close()
+
+	// Configure the authentication client for the test
+	authClient := auth.NewAuthClient("testClientID", "testClientSecret", "dev")
+	authClient.Environment = server.URL
+
+	collect := func(it *participants.Iterator) []string {
+		var ids []string
+		for it.Next() {
+			ids = append(ids, it.Participant().ID)
+		}
+		if it.Err() != nil {
+			t.Errorf("unexpected error: %v", it.Err())
+		}
+		return ids
+	}
+
+	// Walk forward from the first page
+	actual := collect(participants.NewIterator(context.Background(), "testAccessToken", nil, participants.Forward, authClient))
+	expected := []string{"participant1", "participant2", "participant3", "participant4", "participant5", "participant6"}
+	if !helpers.IsEqual(actual, expected) {
+		t.Errorf("expected participants %v, actual participants %v", expected, actual)
+	}
+	if *requests != 3 {
+		t.Errorf("expected 3 requests, got %d", *requests)
+	}
+
+	// Walk backward from the last page
+	filters := &participants.ParticipantsFilter{BeforeCursor: "page3"}
+	actual = collect(participants.NewIterator(context.Background(), "testAccessToken", filters, participants.Backward, authClient))
+	expected = []string{"participant5", "participant6", "participant3", "participant4", "participant1", "participant2"}
+	if !helpers.IsEqual(actual, expected) {
+		t.Errorf("expected participants %v, actual participants %v", expected, actual)
+	}
+
+	// Pages are fetched lazily and cancellation stops the iteration
+	*requests = 0
+	ctx, cancel := context.WithCancel(context.Background())
+	it := participants.NewIterator(ctx, "testAccessToken", nil, participants.Forward, authClient)
+	for i := 0; i < 2; i++ {
+		if !it.Next() {
+			t.Fatalf("unexpected end of iteration: %v", it.Err())
+		}
+	}
+	cancel()
+	if it.Next() || it.Err() != context.Canceled {
+		t.Errorf("expected the iteration canceled, got %v", it.Err())
+	}
+	if *requests != 1 {
+		t.Errorf("expected 1 request, got %d", *requests)
+	}
+}
+
+func TestCollectAllParticipants(t *testing.T) {
+	// Create a test server
+	server, _ := newParticipantsPagesServer(t)
+	defer server.Close()
+
+	// Configure the authentication client for the test
+	authClient := auth.NewAuthClient("testClientID", "testClientSecret", "dev")
+	authClient.Environment = server.URL
+
+	all, err := participants.CollectAll(context.Background(), "testAccessToken", nil, authClient, 0)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(all) != 6 {
+		t.Errorf("expected 6 participants, got %d", len(all))
+	}
+
+	// Stop at the cap
+	all, err = participants.CollectAll(context.Background(), "testAccessToken", nil, authClient, 3)
+	if err != participants.ErrLimitExceeded {
+		t.Errorf("expected ErrLimitExceeded, got %v", err)
+	}
+	if len(all) != 3 {
+		t.Errorf("expected 3 participants, got %d", len(all))
+	}
+}
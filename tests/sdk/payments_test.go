@@ -5,14 +5,20 @@ import (
 	"fmt"
 	"net/http"
 	"net/http/httptest"
+	"strings"
 	"testing"
+	"time"
 
 	"iniciador-sdk/iniciador/auth"
 	"iniciador-sdk/iniciador/payments"
+	"iniciador-sdk/iniciador/utils"
 	"iniciador-sdk/tests/sdk/helpers"
 )
 
 func TestSendGetStatus(t *testing.T) {
+	// Create an access token carrying the payment ID
+	accessToken := helpers.NewAccessToken("testID")
+
 	// Create a test server
 	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		// Verify the request method and path
@@ -23,7 +29,7 @@ func TestSendGetStatus(t *testing.T) {
 
 		// Verify the authorization header
 		authHeader := r.Header.Get("Authorization")
-		expectedAuthHeader := "Bearer testAccessToken"
+		expectedAuthHeader := "Bearer " + accessToken
 		if authHeader != expectedAuthHeader {
 			t.Errorf("expected authorization header to be %s, but got %s", expectedAuthHeader, authHeader)
 		}
@@ -38,6 +44,7 @@ func TestSendGetStatus(t *testing.T) {
 		expectedPayment := payments.PaymentInitiationPayload{
 			ID:     "testID",
 			Amount: 100.0,
+			User:   payments.User{TaxID: "52998224725"},
 		}
 		if !helpers.IsEqual(payment, expectedPayment) {
 			t.Errorf("expected request body: %+v, actual request body: %+v", expectedPayment, payment)
@@ -71,10 +78,11 @@ func TestSendGetStatus(t *testing.T) {
 	payment := &payments.PaymentInitiationPayload{
 		ID:     "testID",
 		Amount: 100.0,
+		User:   payments.User{TaxID: "52998224725"},
 	}
 
 	// Execute the Send function
-	sentPayment, err := payments.Send("testAccessToken", payment, authClient)
+	sentPayment, err := payments.Send(accessToken, payment, authClient)
 	if err != nil {
 		t.Errorf("unexpected error: %v", err)
 	}
@@ -98,7 +106,7 @@ func TestSendGetStatus(t *testing.T) {
 
 		// Verify the authorization header
 		authHeader := r.Header.Get("Authorization")
-		expectedAuthHeader := "Bearer testAccessToken"
+		expectedAuthHeader := "Bearer " + accessToken
 		if authHeader != expectedAuthHeader {
 			t.Errorf("expected authorization header to be %s, but got %s", expectedAuthHeader, authHeader)
 		}
@@ -131,7 +139,7 @@ func TestSendGetStatus(t *testing.T) {
 	authClient.Environment = server.URL
 
 	// Execute the Get function
-	retrievedPayment, err := payments.Get("testAccessToken", authClient)
+	retrievedPayment, err := payments.Get(accessToken, authClient)
 	if err != nil {
 		t.Errorf("unexpected error: %v", err)
 	}
@@ -154,7 +162,7 @@ func TestSendGetStatus(t *testing.T) {
 
 		// Verify the authorization header
 		authHeader := r.Header.Get("Authorization")
-		expectedAuthHeader := "Bearer testAccessToken"
+		expectedAuthHeader := "Bearer " + accessToken
 		if authHeader != expectedAuthHeader {
 			t.Errorf("expected authorization header to be %s, but got %s", expectedAuthHeader, authHeader)
 		}
@@ -186,7 +194,7 @@ func TestSendGetStatus(t *testing.T) {
 	authClient.Environment = server.URL
 
 	// Execute the Status function
-	paymentStatus, err := payments.Status("testAccessToken", authClient)
+	paymentStatus, err := payments.Status(accessToken, authClient)
 	if err != nil {
 		t.Errorf("unexpected error: %v", err)
 	}
@@ -200,3 +208,514 @@ func TestSendGetStatus(t *testing.T) {
 		t.Errorf("expected payment status: %+v, actual payment status: %+v", expectedPaymentStatus, paymentStatus)
 	}
 }
+
+func TestScheduleValidate(t *testing.T) {
+	today := utils.NewDate(2024, time.February, 28)
+
+	testCases := []struct {
+		date    utils.Date
+		isValid bool
+	}{
+		{date: today, isValid: false},
+		{date: today.AddDays(-1), isValid: false},
+		{date: today.AddDays(1), isValid: true},
+		{date: today.AddDays(payments.MaxScheduleDays), isValid: true},
+		{date: today.AddDays(payments.MaxScheduleDays + 1), isValid: false},
+		{date: utils.Date{}, isValid: false},
+	}
+
+	for _, testCase := range testCases {
+		schedule := &payments.Schedule{Single: &payments.SingleSchedule{Date: testCase.date}}
+		err := schedule.Validate(today)
+		if testCase.isValid && err != nil {
+			t.Errorf("expected %s to be a valid schedule date, got error: %v", testCase.date, err)
+		}
+		if !testCase.isValid && err == nil {
+			t.Errorf("expected %s to be an invalid schedule date", testCase.date)
+		}
+	}
+}
+
+func TestSendScheduled(t *testing.T) {
+	scheduledDate := utils.TodayInBrazil().AddDays(7)
+
+	// Create a test server
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		// Verify the schedule in the request body
+		var requestBody map[string]interface{}
+		err := json.NewDecoder(r.Body).Decode(&requestBody)
+		if err != nil {
+			t.Errorf("failed to decode the request body: %v", err)
+		}
+
+		expectedSchedule := map[string]interface{}{
+			"single": map[string]interface{}{"date": scheduledDate.String()},
+		}
+		if !helpers.IsEqual(requestBody["schedule"], expectedSchedule) {
+			t.Errorf("expected schedule: %v, actual schedule: %v", expectedSchedule, requestBody["schedule"])
+		}
+
+		// Create a simulated response
+		status := payments.PaymentInitiationStatus(payments.PaymentScheduled)
+		paymentInitiationPayload := payments.PaymentInitiationPayload{
+			ID:     "testID",
+			Status: &status,
+			Date:   scheduledDate,
+		}
+		responseBody, err := json.Marshal(paymentInitiationPayload)
+		if err != nil {
+			t.Errorf("failed to encode the response: %v", err)
+		}
+
+		// Send the simulated response
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusOK)
+		_, _ = w.Write(responseBody)
+	}))
+	defer server.Close()
+
+	// Configure the authentication client for the test
+	authClient := auth.NewAuthClient("testClientID", "testClientSecret", "dev")
+	authClient.Environment = server.URL
+
+	// Set up the scheduled payment payload
+	schedule, err := payments.NewSingleSchedule(scheduledDate)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	payment := &payments.PaymentInitiationPayload{
+		Amount:   100.0,
+		User:     payments.User{TaxID: "52998224725"},
+		Schedule: schedule,
+	}
+
+	// Execute the Send function
+	sentPayment, err := payments.Send("testAccessToken", payment, authClient)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	// Verify the scheduled date
+	date, ok := sentPayment.ScheduledDate()
+	if !ok || date != scheduledDate {
+		t.Errorf("expected scheduled date %s, actual scheduled date %s", scheduledDate, date)
+	}
+
+	// Verify that an out of range schedule is rejected before sending
+	payment.Schedule = &payments.Schedule{Single: &payments.SingleSchedule{Date: utils.TodayInBrazil()}}
+	_, err = payments.Send("testAccessToken", payment, authClient)
+	if err == nil {
+		t.Errorf("expected an error for a schedule date of today")
+	}
+
+	// Verify that a date other than the schedule date is rejected
+	payment.Schedule = schedule
+	payment.Date = scheduledDate.AddDays(1)
+	_, err = payments.Send("testAccessToken", payment, authClient)
+	if err == nil {
+		t.Errorf("expected an error for a date other than the schedule date")
+	}
+
+	// Verify that a future date without a schedule follows the schedule window
+	payment.Schedule = nil
+	payment.Date = utils.TodayInBrazil().AddDays(payments.MaxScheduleDays + 1)
+	_, err = payments.Send("testAccessToken", payment, authClient)
+	if err == nil {
+		t.Errorf("expected an error for a date beyond the schedule window")
+	}
+}
+
+func TestScheduleOccurrences(t *testing.T) {
+	startDate := utils.NewDate(2024, time.January, 31)
+
+	testCases := []struct {
+		name     string
+		schedule *payments.Schedule
+		expected []utils.Date
+	}{
+		{
+			name:     "daily",
+			schedule: &payments.Schedule{Daily: &payments.DailySchedule{StartDate: startDate, Quantity: 3}},
+			expected: []utils.Date{startDate, utils.NewDate(2024, time.February, 1), utils.NewDate(2024, time.February, 2)},
+		},
+		{
+			name:     "weekly",
+			schedule: &payments.Schedule{Weekly: &payments.WeeklySchedule{DayOfWeek: payments.Monday, StartDate: startDate, Quantity: 2}},
+			expected: []utils.Date{utils.NewDate(2024, time.February, 5), utils.NewDate(2024, time.February, 12)},
+		},
+		{
+			name:     "monthly",
+			schedule: &payments.Schedule{Monthly: &payments.MonthlySchedule{DayOfMonth: 31, StartDate: startDate, Quantity: 3}},
+			expected: []utils.Date{startDate, utils.NewDate(2024, time.February, 29), utils.NewDate(2024, time.March, 31)},
+		},
+		{
+			name:     "custom",
+			schedule: &payments.Schedule{Custom: &payments.CustomSchedule{Dates: []utils.Date{startDate, startDate.AddDays(10)}}},
+			expected: []utils.Date{startDate, startDate.AddDays(10)},
+		},
+	}
+
+	for _, testCase := range testCases {
+		occurrences, err := testCase.schedule.Occurrences()
+		if err != nil {
+			t.Errorf("%s: unexpected error: %v", testCase.name, err)
+			continue
+		}
+		if !helpers.IsEqual(occurrences, testCase.expected) {
+			t.Errorf("%s: expected occurrences %v, actual occurrences %v", testCase.name, testCase.expected, occurrences)
+		}
+	}
+
+	// Verify the quantity limits
+	schedule := &payments.Schedule{Daily: &payments.DailySchedule{StartDate: startDate, Quantity: payments.MaxDailyQuantity + 1}}
+	if _, err := schedule.Occurrences(); err == nil {
+		t.Errorf("expected an error for a daily schedule above the quantity limit")
+	}
+
+	// Verify that custom dates must be ascending
+	schedule = &payments.Schedule{Custom: &payments.CustomSchedule{Dates: []utils.Date{startDate, startDate}}}
+	if _, err := schedule.Occurrences(); err == nil {
+		t.Errorf("expected an error for repeated custom dates")
+	}
+}
+
+func TestSendRecurring(t *testing.T) {
+	startDate := utils.TodayInBrazil().AddDays(1)
+
+	// Create a test server
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var response interface{}
+
+		// Verify the request method and path
+		switch {
+		case r.Method == http.MethodPost && r.URL.Path == "/payments/recurring":
+			var payment payments.PaymentInitiationPayload
+			err := json.NewDecoder(r.Body).Decode(&payment)
+			if err != nil {
+				t.Errorf("failed to decode the request body: %v", err)
+			}
+			response = payments.RecurringPaymentPayload{
+				ID:       "testRecurringID",
+				Amount:   payment.Amount,
+				Schedule: payment.Schedule,
+			}
+		case r.Method == http.MethodPatch && r.URL.Path == "/payments/recurring/testRecurringID/payments/testPaymentID":
+			var requestBody map[string]interface{}
+			err := json.NewDecoder(r.Body).Decode(&requestBody)
+			if err != nil {
+				t.Errorf("failed to decode the request body: %v", err)
+			}
+			if requestBody["status"] != string(payments.Canceled) {
+				t.Errorf("expected status %s, but got %v", payments.Canceled, requestBody["status"])
+			}
+			status := payments.PaymentInitiationStatus(payments.Canceled)
+			response = payments.PaymentInitiationPayload{ID: "testPaymentID", Status: &status}
+		default:
+			t.Errorf("unexpected request %s %s", r.Method, r.URL.Path)
+		}
+
+		responseBody, err := json.Marshal(response)
+		if err != nil {
+			t.Errorf("failed to encode the response: %v", err)
+		}
+
+		// Send the simulated response
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusOK)
+		_, _ = w.Write(responseBody)
+	}))
+	defer server.Close()
+
+	// Configure the authentication client for the test
+	authClient := auth.NewAuthClient("testClientID", "testClientSecret", "dev")
+	authClient.Environment = server.URL
+
+	// Set up the recurring payment payload
+	schedule, err := payments.NewWeeklySchedule(payments.Friday, startDate, 4)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	payment := &payments.PaymentInitiationPayload{
+		Amount:   100.0,
+		User:     payments.User{TaxID: "52998224725"},
+		Schedule: schedule,
+	}
+
+	// Verify that Send refuses recurring schedules
+	_, err = payments.Send("testAccessToken", payment, authClient)
+	if err == nil {
+		t.Errorf("expected Send to reject a recurring schedule")
+	}
+
+	// Execute the SendRecurring function
+	recurring, err := payments.SendRecurring("testAccessToken", payment, authClient)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	occurrences, err := recurring.Schedule.Occurrences()
+	if err != nil || len(occurrences) != 4 {
+		t.Errorf("expected 4 occurrences, got %v (error: %v)", occurrences, err)
+	}
+
+	// Execute the CancelOccurrence function
+	canceled, err := payments.CancelOccurrence("testAccessToken", recurring.ID, "testPaymentID", authClient)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if canceled.Status == nil || *canceled.Status != payments.Canceled {
+		t.Errorf("expected canceled occurrence, got %+v", canceled)
+	}
+}
+
+func TestPaymentMethodValidate(t *testing.T) {
+	creditor := &payments.BankAccount{
+		ISPB:        "12345678",
+		Issuer:      "0001",
+		Number:      "123456",
+		AccountType: payments.CheckingAccount,
+	}
+
+	testCases := []struct {
+		name    string
+		payment payments.PaymentInitiationPayload
+		isValid bool
+	}{
+		{name: "manual with creditor", payment: payments.PaymentInitiationPayload{Method: payments.PixManual, Creditor: creditor}, isValid: true},
+		{name: "manual without creditor", payment: payments.PaymentInitiationPayload{Method: payments.PixManual}, isValid: true},
+		{name: "manual without account type", payment: payments.PaymentInitiationPayload{Method: payments.PixManual, Creditor: &payments.BankAccount{ISPB: "12345678", Issuer: "0001", Number: "123456"}}, isValid: false},
+		{name: "dict with key", payment: payments.PaymentInitiationPayload{Method: payments.PixDict, PixKey: "john@example.com"}, isValid: true},
+		{name: "dict without key", payment: payments.PaymentInitiationPayload{Method: payments.PixDict}, isValid: false},
+		{name: "static qr code", payment: payments.PaymentInitiationPayload{Method: payments.PixStaticQRCode, QRCode: "testQRCode"}, isValid: true},
+		{name: "dynamic qr code without code", payment: payments.PaymentInitiationPayload{Method: payments.PixDynamicQRCode}, isValid: false},
+		{name: "initiator with creditor", payment: payments.PaymentInitiationPayload{Method: payments.PixInitiator, Creditor: creditor}, isValid: true},
+		{name: "initiator without creditor", payment: payments.PaymentInitiationPayload{Method: payments.PixInitiator}, isValid: false},
+		{name: "unknown method", payment: payments.PaymentInitiationPayload{Method: "PIX_BOLETO"}, isValid: false},
+	}
+
+	for _, testCase := range testCases {
+		payment := testCase.payment
+		payment.Amount = 1000
+		payment.User = payments.User{TaxID: "52998224725"}
+
+		err := payment.Validate()
+		if testCase.isValid && err != nil {
+			t.Errorf("%s: unexpected error: %v", testCase.name, err)
+		}
+		if !testCase.isValid && err == nil {
+			t.Errorf("%s: expected a validation error", testCase.name)
+		}
+	}
+}
+
+func TestSendManualPayment(t *testing.T) {
+	// Create a test server
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusOK)
+		_, _ = w.Write([]byte(`{"id":"testID","status":"STARTED"}`))
+	}))
+	defer server.Close()
+
+	// Configure the authentication client for the test
+	authClient := auth.NewAuthClient("testClientID", "testClientSecret", "dev")
+	authClient.Environment = server.URL
+
+	// Send the README payload, leaving the creditor to the hosted interface
+	payment := &payments.PaymentInitiationPayload{
+		ExternalID:    "externalId",
+		ParticipantID: "c8f0bf49-4744-4933-8960-7add6e590841",
+		RedirectURL:   "https://app.sandbox.inic.dev/pag-receipt",
+		User: payments.User{
+			Name:  "John Doe",
+			TaxID: "52998224725",
+		},
+		Amount: 133300,
+		Method: payments.PixManual,
+	}
+	output, err := payments.Send("testAccessToken", payment, authClient)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if output.ID != "testID" {
+		t.Errorf("expected payment testID, got %+v", output)
+	}
+}
+
+func TestPaymentValidate(t *testing.T) {
+	// Set up a payment with several invalid fields
+	payment := &payments.PaymentInitiationPayload{
+		Amount:      10.5,
+		User:        payments.User{TaxID: "12345678900"},
+		RedirectURL: "/receipt",
+		Description: strings.Repeat("a", payments.MaxDescriptionLength+1),
+		Creditor: &payments.BankAccount{
+			ISPB:   "1234",
+			Issuer: "00001",
+			Number: "12-3",
+		},
+	}
+
+	err := payment.Validate()
+	validationErrors, ok := err.(payments.ValidationErrors)
+	if !ok {
+		t.Fatalf("expected ValidationErrors, got %v", err)
+	}
+
+	expectedErrors := map[string]string{
+		"amount":               payments.RuleCents,
+		"user.taxId":           payments.RuleDocument,
+		"creditor.ispb":        payments.RuleISPB,
+		"creditor.issuer":      payments.RuleBranch,
+		"creditor.number":      payments.RuleAccount,
+		"creditor.accountType": payments.RuleRequired,
+		"redirectURL":          payments.RuleURL,
+		"description":          payments.RuleMaxLength,
+	}
+	actualErrors := make(map[string]string)
+	for _, fieldError := range validationErrors {
+		actualErrors[fieldError.Path] = fieldError.Rule
+	}
+	if !helpers.IsEqual(actualErrors, expectedErrors) {
+		t.Errorf("expected errors %v, actual errors %v", expectedErrors, actualErrors)
+	}
+
+	// Verify that a formatted CNPJ and a complete creditor are valid
+	payment = &payments.PaymentInitiationPayload{
+		Amount:      1000,
+		User:        payments.User{TaxID: "11.222.333/0001-81"},
+		RedirectURL: "https://example.com/receipt",
+		Creditor: &payments.BankAccount{
+			ISPB:        "12345678",
+			Issuer:      "0001",
+			Number:      "1234567",
+			AccountType: payments.SavingsAccount,
+		},
+	}
+	if err := payment.Validate(); err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+
+	// Verify that the date must agree with a single schedule and stay within the schedule window
+	today := utils.TodayInBrazil()
+	dateCases := []struct {
+		date     utils.Date
+		schedule *payments.Schedule
+		isValid  bool
+	}{
+		{date: today, isValid: true},
+		{date: today.AddDays(7), isValid: true},
+		{date: today.AddDays(payments.MaxScheduleDays + 1), isValid: false},
+		{date: today.AddDays(7), schedule: &payments.Schedule{Single: &payments.SingleSchedule{Date: today.AddDays(7)}}, isValid: true},
+		{date: today.AddDays(3), schedule: &payments.Schedule{Single: &payments.SingleSchedule{Date: today.AddDays(7)}}, isValid: false},
+	}
+	for _, dateCase := range dateCases {
+		payment.Date = dateCase.date
+		payment.Schedule = dateCase.schedule
+		err := payment.Validate()
+		if dateCase.isValid && err != nil {
+			t.Errorf("expected date %s to be valid, got error: %v", dateCase.date, err)
+		}
+		if !dateCase.isValid {
+			validationErrors, ok := err.(payments.ValidationErrors)
+			if !ok || len(validationErrors) != 1 || validationErrors[0].Path != "date" || validationErrors[0].Rule != payments.RuleSchedule {
+				t.Errorf("expected a date schedule error for %s, got %v", dateCase.date, err)
+			}
+		}
+	}
+}
+
+func TestSendValidation(t *testing.T) {
+	requests := 0
+
+	// Create a test server
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusOK)
+		_, _ = w.Write([]byte(`{"id":"testID"}`))
+	}))
+	defer server.Close()
+
+	// Configure the authentication client for the test
+	authClient := auth.NewAuthClient("testClientID", "testClientSecret", "dev")
+	authClient.Environment = server.URL
+
+	// Verify that invalid payments are not sent
+	payment := &payments.PaymentInitiationPayload{Amount: 0}
+	_, err := payments.Send("testAccessToken", payment, authClient)
+	if _, ok := err.(payments.ValidationErrors); !ok {
+		t.Errorf("expected ValidationErrors, got %v", err)
+	}
+	if requests != 0 {
+		t.Errorf("expected no requests, got %d", requests)
+	}
+
+	// Verify that validation can be disabled
+	_, err = payments.Send("testAccessToken", payment, authClient, payments.WithoutValidation())
+	if err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+	if requests != 1 {
+		t.Errorf("expected 1 request, got %d", requests)
+	}
+}
+
+func TestBuilder(t *testing.T) {
+	payer := payments.User{Name: "Jane Doe", TaxID: "52998224725"}
+
+	// Build a DICT payment
+	payment, err := payments.ToPixKey("john@example.com").
+		Amount(1500).
+		Payer(payer).
+		Description("Order 42").
+		Metadata(payments.Metadata{"orderId": "42"}).
+		RedirectURLs("https://example.com/success", "https://example.com/error").
+		Build()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	expectedPayment := &payments.PaymentInitiationPayload{
+		Method:             payments.PixDict,
+		PixKey:             "john@example.com",
+		Amount:             1500,
+		User:               payer,
+		Description:        "Order 42",
+		Metadata:           payments.Metadata{"orderId": "42"},
+		RedirectURL:        "https://example.com/success",
+		RedirectOnErrorURL: "https://example.com/error",
+	}
+	if !helpers.IsEqual(payment, expectedPayment) {
+		t.Errorf("expected payment: %+v, actual payment: %+v", expectedPayment, payment)
+	}
+
+	// Build a scheduled payment to an account
+	scheduledDate := utils.TodayInBrazil().AddDays(3)
+	payment, err = payments.ToAccount(payments.BankAccount{
+		ISPB:        "12345678",
+		Issuer:      "0001",
+		Number:      "123456",
+		AccountType: payments.CheckingAccount,
+	}).Amount(2000).Payer(payer).ScheduleOn(scheduledDate).Build()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if payment.Method != payments.PixManual || payment.Creditor == nil || payment.Schedule.Single.Date != scheduledDate {
+		t.Errorf("unexpected scheduled payment: %+v", payment)
+	}
+
+	// Verify that missing required fields are reported
+	_, err = payments.ToQRCode("").Build()
+	validationErrors, ok := err.(payments.ValidationErrors)
+	if !ok {
+		t.Fatalf("expected ValidationErrors, got %v", err)
+	}
+	paths := make([]string, len(validationErrors))
+	for i, fieldError := range validationErrors {
+		paths[i] = fieldError.Path
+	}
+	expectedPaths := []string{"amount", "user.taxId", "qrCode"}
+	if !helpers.IsEqual(paths, expectedPaths) {
+		t.Errorf("expected invalid fields %v, actual invalid fields %v", expectedPaths, paths)
+	}
+}
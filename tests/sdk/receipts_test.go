@@ -0,0 +1,153 @@
+package sdk
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+
+	"iniciador-sdk/iniciador/payments"
+	"iniciador-sdk/iniciador/receipts"
+	"iniciador-sdk/iniciador/utils"
+)
+
+func TestReceipts(t *testing.T) {
+	status := payments.PaymentInitiationStatus(payments.PaymentCompleted)
+	payment := &payments.PaymentInitiationPayload{
+		ID:                        "testPaymentID",
+		Status:                    &status,
+		CreatedAt:                 utils.NewTimestamp(time.Date(2024, time.March, 1, 2, 20, 0, 0, time.UTC)),
+		UpdatedAt:                 utils.NewTimestamp(time.Date(2024, time.March, 1, 2, 30, 0, 0, time.UTC)),
+		EndToEndID:                "E12345678202402292330abcdefghijk",
+		TransactionIdentification: "testTxID",
+		Amount:                    123456,
+		Description:               "Pedido <42> & cia",
+		Provider:                  &payments.Provider{TradeName: "Test Provider"},
+		User:                      payments.User{Name: "João da Silva", TaxID: "52998224725"},
+		Debtor:                    &payments.BankAccount{ISPB: "12345678", Issuer: "0001", Number: "123456", Bank: &payments.Bank{Name: "Test Bank"}},
+		Creditor:                  &payments.BankAccount{Name: "Loja Ação", TaxID: "11222333000181", ISPB: "87654321", Issuer: "0002", Number: "654321"},
+	}
+
+	receipt, err := receipts.New(payment)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	// Verify the plain text receipt
+	text := receipt.Text()
+	for _, expected := range []string{
+		"Valor: R$ 1.234,56",
+		"Data e hora: 29/02/2024 23:30:00",
+		"CPF/CNPJ: ***.982.247-**",
+		"CPF/CNPJ: **.222.333/0001-**",
+		"Instituição: Test Bank",
+		"Instituição: ISPB 87654321",
+		"ID da transação: E12345678202402292330abcdefghijk",
+		"Iniciador: Test Provider",
+	} {
+		if !strings.Contains(text, expected) {
+			t.Errorf("expected text receipt to contain %q, got:\n%s", expected, text)
+		}
+	}
+	if strings.Contains(text, "52998224725") || strings.Contains(text, "11222333000181") {
+		t.Errorf("expected documents to be masked, got:\n%s", text)
+	}
+
+	// Verify that the HTML receipt escapes the payment fields
+	html, err := receipt.HTML()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !bytes.Contains(html, []byte("Pedido &lt;42&gt; &amp; cia")) {
+		t.Errorf("expected the description to be escaped, got:\n%s", html)
+	}
+
+	// Verify the PDF structure and the Latin-1 encoding of accents
+	pdf, err := receipt.PDF()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !bytes.HasPrefix(pdf, []byte("%PDF-1.4\n")) || !bytes.HasSuffix(pdf, []byte("%%EOF\n")) {
+		t.Errorf("expected a PDF document, got:\n%s", pdf)
+	}
+	if !bytes.Contains(pdf, []byte(`(Jo\343o da Silva)`)) {
+		t.Errorf("expected the payer name in WinAnsiEncoding, got:\n%s", pdf)
+	}
+
+	// Verify that the payment time falls back to the creation time
+	payment.UpdatedAt = utils.Timestamp{}
+	receipt, _ = receipts.New(payment)
+	if !strings.Contains(receipt.Text(), "Data e hora: 29/02/2024 23:20:00") {
+		t.Errorf("expected the creation time without an update time, got:\n%s", receipt.Text())
+	}
+
+	// Verify that only completed payments have receipts
+	pending := payments.PaymentInitiationStatus(payments.PaymentPending)
+	payment.Status = &pending
+	_, err = receipts.New(payment)
+	if err == nil {
+		t.Errorf("expected an error for a pending payment")
+	}
+}
+
+func TestReceiptPDFWrapping(t *testing.T) {
+	status := payments.PaymentInitiationStatus(payments.PaymentCompleted)
+	payment := &payments.PaymentInitiationPayload{
+		ID:          "testPaymentID",
+		Status:      &status,
+		EndToEndID:  "E12345678202402292330abcdefghijk",
+		Amount:      100,
+		Description: strings.Repeat("palavra ", 100),
+		User:        payments.User{Name: strings.Repeat("W", 200), TaxID: "52998224725"},
+	}
+	receipt, err := receipts.New(payment)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	pdf, err := receipt.PDF()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	// Long values are wrapped within the page and cut after four lines
+	var descriptionLines, nameLines []string
+	for _, line := range strings.Split(string(pdf), "\n") {
+		if !strings.HasPrefix(line, "BT /F1 10 Tf 196 ") {
+			continue
+		}
+		text := line[strings.Index(line, "(")+1 : strings.LastIndex(line, ")")]
+		switch {
+		case strings.HasPrefix(text, "palavra"):
+			descriptionLines = append(descriptionLines, text)
+		case strings.HasPrefix(text, "W"):
+			nameLines = append(nameLines, text)
+		}
+	}
+	if len(descriptionLines) != 4 || !strings.HasSuffix(descriptionLines[3], "...") {
+		t.Errorf("expected the description in 4 lines ending with ..., got %q", descriptionLines)
+	}
+	for _, line := range descriptionLines {
+		if len(line) > 75 {
+			t.Errorf("expected description lines to fit the page, got %q", line)
+		}
+	}
+	if len(nameLines) != 4 || len(nameLines[0]) > 40 {
+		t.Errorf("expected the name broken within the page, got %q", nameLines)
+	}
+}
+
+func TestFormatBRL(t *testing.T) {
+	testCases := map[float64]string{
+		0:         "R$ 0,00",
+		5:         "R$ 0,05",
+		100:       "R$ 1,00",
+		123456:    "R$ 1.234,56",
+		100000000: "R$ 1.000.000,00",
+		-1050:     "-R$ 10,50",
+	}
+	for amount, expected := range testCases {
+		if actual := utils.FormatBRL(amount); actual != expected {
+			t.Errorf("expected %v to be formatted as %s, got %s", amount, expected, actual)
+		}
+	}
+}
@@ -0,0 +1,118 @@
+package sdk
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+
+	"iniciador-sdk/iniciador/exports"
+	"iniciador-sdk/iniciador/payments"
+	"iniciador-sdk/iniciador/reconciliation"
+)
+
+func reconciliationRecords() []*exports.Record {
+	paidAt := time.Date(2024, time.March, 1, 12, 0, 0, 0, time.UTC)
+	completed := string(payments.PaymentCompleted)
+	return []*exports.Record{
+		{ID: "matched", EndToEndID: "E12345678202403011200aaaaaaaaaaa", Status: completed, Amount: 1000, CreatedAt: paidAt},
+		{ID: "mismatched", EndToEndID: "E12345678202403011200bbbbbbbbbbb", Status: completed, Amount: 2000, CreatedAt: paidAt},
+		{ID: "duplicated", EndToEndID: "E12345678202403011200ccccccccccc", Status: completed, Amount: 3000, CreatedAt: paidAt},
+		{ID: "heuristic", Status: completed, Amount: 4000, PayerTaxID: "52998224725", CreatedAt: paidAt},
+		{ID: "missing", EndToEndID: "E12345678202403011200ddddddddddd", Status: completed, Amount: 5000, CreatedAt: paidAt},
+		{ID: "pending", Status: string(payments.PaymentPending), Amount: 6000, CreatedAt: paidAt},
+	}
+}
+
+func TestReconcile(t *testing.T) {
+	postedAt := time.Date(2024, time.March, 1, 15, 0, 0, 0, time.UTC)
+	entries := reconciliation.Entries{
+		{ID: "1", EndToEndID: "E12345678202403011200aaaaaaaaaaa", Amount: 1000, PostedAt: postedAt},
+		{ID: "2", EndToEndID: "E12345678202403011200bbbbbbbbbbb", Amount: 2500, PostedAt: postedAt},
+		{ID: "3", EndToEndID: "E12345678202403011200ccccccccccc", Amount: 3000, PostedAt: postedAt},
+		{ID: "4", EndToEndID: "E12345678202403011200ccccccccccc", Amount: 3000, PostedAt: postedAt},
+		{ID: "5", Amount: 4000, CounterpartyTaxID: "529.982.247-25", PostedAt: postedAt.AddDate(0, 0, 1)},
+		{ID: "6", Amount: 6000, PostedAt: postedAt},
+		{ID: "7", Amount: -1000, PostedAt: postedAt},
+	}
+
+	report, err := reconciliation.Reconcile(reconciliationRecords(), entries, nil)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if len(report.Matched) != 3 ||
+		report.Matched[0].Payment.ID != "matched" || report.Matched[0].Method != reconciliation.ByEndToEndID ||
+		report.Matched[1].Payment.ID != "duplicated" ||
+		report.Matched[2].Payment.ID != "heuristic" || report.Matched[2].Method != reconciliation.ByHeuristic {
+		t.Errorf("unexpected matches %+v", report.Matched)
+	}
+	if len(report.AmountMismatched) != 1 || report.AmountMismatched[0].Payment.ID != "mismatched" {
+		t.Errorf("unexpected amount mismatches %+v", report.AmountMismatched)
+	}
+	if len(report.Duplicated) != 1 || report.Duplicated[0].Payment.ID != "duplicated" || len(report.Duplicated[0].Entries) != 1 || report.Duplicated[0].Entries[0].ID != "4" {
+		t.Errorf("unexpected duplicates %+v", report.Duplicated)
+	}
+	if len(report.Missing) != 1 || report.Missing[0].ID != "missing" {
+		t.Errorf("unexpected missing payments %+v", report.Missing)
+	}
+	if len(report.Unexpected) != 1 || report.Unexpected[0].ID != "6" {
+		t.Errorf("unexpected entries %+v", report.Unexpected)
+	}
+	if report.IsReconciled() {
+		t.Errorf("expected the report not to be reconciled")
+	}
+
+	// Verify that heuristics can be disabled
+	report, err = reconciliation.Reconcile(reconciliationRecords(), entries, &reconciliation.Options{DisableHeuristics: true})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(report.Matched) != 2 || len(report.Missing) != 2 {
+		t.Errorf("expected only matches by EndToEndID, got %+v", report)
+	}
+}
+
+func TestReconcileSources(t *testing.T) {
+	records := reconciliationRecords()[:1]
+
+	// Reconcile an OFX statement written by exports
+	var buffer bytes.Buffer
+	err := exports.WriteAll(exports.NewOFXWriter(&buffer, exports.OFXStatement{BankID: "12345678", AccountID: "123456"}), records)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	report, err := reconciliation.Reconcile(records, &reconciliation.OFXSource{Reader: &buffer}, nil)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !report.IsReconciled() || len(report.Matched) != 1 || report.Matched[0].Entry.Amount != 1000 {
+		t.Errorf("expected the OFX statement to reconcile, got %+v", report)
+	}
+
+	// Reconcile an SGML OFX statement with the EndToEndID in the memo
+	sgml := "OFXHEADER:100\nDATA:OFXSGML\n\n<OFX><BANKMSGSRSV1><STMTTRNRS><STMTRS><BANKTRANLIST>\n" +
+		"<STMTTRN>\n<TRNTYPE>CREDIT\n<DTPOSTED>20240301090000[-3:BRT]\n<TRNAMT>10,00\n<FITID>123\n<MEMO>PIX RECEBIDO E12345678202403011200aaaaaaaaaaa\n</STMTTRN>\n" +
+		"</BANKTRANLIST></STMTRS></STMTTRNRS></BANKMSGSRSV1></OFX>\n"
+	report, err = reconciliation.Reconcile(records, &reconciliation.OFXSource{Reader: strings.NewReader(sgml)}, nil)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !report.IsReconciled() || report.Matched[0].Method != reconciliation.ByEndToEndID {
+		t.Errorf("expected the SGML statement to reconcile, got %+v", report)
+	}
+
+	// Reconcile a CSV statement in Brazilian format
+	source := reconciliation.NewCSVSource(strings.NewReader("data;valor;e2e\n01/03/2024;\"1.000,00\";E12345678202403011200aaaaaaaaaaa\n"))
+	source.Comma = ';'
+	source.PostedAtColumn = "data"
+	source.AmountColumn = "valor"
+	source.EndToEndIDColumn = "e2e"
+	report, err = reconciliation.Reconcile(records, source, nil)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(report.AmountMismatched) != 1 || report.AmountMismatched[0].Entry.Amount != 100000 {
+		t.Errorf("expected an amount mismatch of R$ 1.000,00, got %+v", report)
+	}
+}
@@ -0,0 +1,172 @@
+package sdk
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"iniciador-sdk/iniciador/auth"
+	"iniciador-sdk/iniciador/payments"
+	"iniciador-sdk/iniciador/recurringconsents"
+	"iniciador-sdk/iniciador/utils"
+)
+
+func TestRecurringConsentValidate(t *testing.T) {
+	today := utils.NewDate(2024, time.January, 15)
+
+	testCases := []struct {
+		name    string
+		consent recurringconsents.RecurringConsentPayload
+		isValid bool
+	}{
+		{
+			name:    "fixed amount",
+			consent: recurringconsents.RecurringConsentPayload{FixedAmount: 1000, Periodicity: recurringconsents.Monthly, StartDate: today.AddDays(1)},
+			isValid: true,
+		},
+		{
+			name:    "variable amount without max",
+			consent: recurringconsents.RecurringConsentPayload{Periodicity: recurringconsents.Weekly, StartDate: today.AddDays(1)},
+			isValid: true,
+		},
+		{
+			name:    "fixed and max amount",
+			consent: recurringconsents.RecurringConsentPayload{FixedAmount: 1000, MaxAmount: 2000, Periodicity: recurringconsents.Monthly, StartDate: today.AddDays(1)},
+			isValid: false,
+		},
+		{
+			name:    "fractional cents",
+			consent: recurringconsents.RecurringConsentPayload{MaxAmount: 10.5, Periodicity: recurringconsents.Monthly, StartDate: today.AddDays(1)},
+			isValid: false,
+		},
+		{
+			name:    "unknown periodicity",
+			consent: recurringconsents.RecurringConsentPayload{FixedAmount: 1000, Periodicity: "DIARIO", StartDate: today.AddDays(1)},
+			isValid: false,
+		},
+		{
+			name:    "start date today",
+			consent: recurringconsents.RecurringConsentPayload{FixedAmount: 1000, Periodicity: recurringconsents.Monthly, StartDate: today},
+			isValid: false,
+		},
+		{
+			name:    "expiration before first period",
+			consent: recurringconsents.RecurringConsentPayload{FixedAmount: 1000, Periodicity: recurringconsents.Annual, StartDate: today.AddDays(1), ExpirationDate: today.AddDays(100)},
+			isValid: false,
+		},
+	}
+
+	for _, testCase := range testCases {
+		err := testCase.consent.Validate(today)
+		if testCase.isValid && err != nil {
+			t.Errorf("%s: unexpected error: %v", testCase.name, err)
+		}
+		if !testCase.isValid && err == nil {
+			t.Errorf("%s: expected a validation error", testCase.name)
+		}
+	}
+}
+
+func TestRecurringConsentCreateAndCharge(t *testing.T) {
+	startDate := utils.TodayInBrazil().AddDays(1)
+
+	// Create a test server
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var response interface{}
+
+		// Verify the request method and path
+		switch {
+		case r.Method == http.MethodPost && r.URL.Path == "/recurring-consents":
+			var consent recurringconsents.RecurringConsentPayload
+			err := json.NewDecoder(r.Body).Decode(&consent)
+			if err != nil {
+				t.Errorf("failed to decode the request body: %v", err)
+			}
+			if consent.StartDate != startDate || consent.Periodicity != recurringconsents.Monthly {
+				t.Errorf("unexpected consent in request body: %+v", consent)
+			}
+			consent.ID = "testConsentID"
+			consent.Status = recurringconsents.AwaitingAuthorization
+			response = consent
+		case r.Method == http.MethodPost && r.URL.Path == "/recurring-consents/testConsentID/payments":
+			var charge recurringconsents.ChargeInput
+			err := json.NewDecoder(r.Body).Decode(&charge)
+			if err != nil {
+				t.Errorf("failed to decode the request body: %v", err)
+			}
+			response = payments.PaymentInitiationPayload{ID: "testPaymentID", ConsentID: "testConsentID", Amount: charge.Amount}
+		case r.Method == http.MethodPatch && r.URL.Path == "/recurring-consents/testConsentID":
+			var requestBody map[string]interface{}
+			err := json.NewDecoder(r.Body).Decode(&requestBody)
+			if err != nil {
+				t.Errorf("failed to decode the request body: %v", err)
+			}
+			if requestBody["status"] != string(recurringconsents.Revoked) {
+				t.Errorf("expected status %s, but got %v", recurringconsents.Revoked, requestBody["status"])
+			}
+			response = recurringconsents.RecurringConsentPayload{ID: "testConsentID", Status: recurringconsents.Revoked}
+		default:
+			t.Errorf("unexpected request %s %s", r.Method, r.URL.Path)
+		}
+
+		responseBody, err := json.Marshal(response)
+		if err != nil {
+			t.Errorf("failed to encode the response: %v", err)
+		}
+
+		// Send the simulated response
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusOK)
+		_, _ = w.Write(responseBody)
+	}))
+	defer server.Close()
+
+	// Configure the authentication client for the test
+	authClient := auth.NewAuthClient("testClientID", "testClientSecret", "dev")
+	authClient.Environment = server.URL
+
+	// Execute the Create function
+	consent, err := recurringconsents.Create("testAccessToken", &recurringconsents.RecurringConsentPayload{
+		User:        payments.User{TaxID: "testTaxID"},
+		MaxAmount:   5000,
+		Periodicity: recurringconsents.Monthly,
+		StartDate:   startDate,
+	}, authClient)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	// Verify that charges require an authorized consent
+	charge := &recurringconsents.ChargeInput{Amount: 4000}
+	_, err = recurringconsents.Charge("testAccessToken", consent, charge, authClient)
+	if err == nil {
+		t.Errorf("expected an error charging a consent awaiting authorization")
+	}
+
+	// Verify that charges above the max amount are rejected
+	consent.Status = recurringconsents.Authorized
+	_, err = recurringconsents.Charge("testAccessToken", consent, &recurringconsents.ChargeInput{Amount: 6000}, authClient)
+	if err == nil {
+		t.Errorf("expected an error charging above the max amount")
+	}
+
+	// Execute the Charge function
+	payment, err := recurringconsents.Charge("testAccessToken", consent, charge, authClient)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if payment.ConsentID != consent.ID || payment.Amount != charge.Amount {
+		t.Errorf("unexpected charge payment: %+v", payment)
+	}
+
+	// Execute the Revoke function
+	revoked, err := recurringconsents.Revoke("testAccessToken", consent.ID, "customer request", authClient)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if revoked.Status != recurringconsents.Revoked {
+		t.Errorf("expected status %s, actual status %s", recurringconsents.Revoked, revoked.Status)
+	}
+}
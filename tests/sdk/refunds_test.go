@@ -0,0 +1,110 @@
+package sdk
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"iniciador-sdk/iniciador/auth"
+	"iniciador-sdk/iniciador/payments"
+	"iniciador-sdk/iniciador/refunds"
+)
+
+func TestRefunds(t *testing.T) {
+	// Keep the refunds made so far, as the API would
+	existingRefunds := []refunds.RefundPayload{
+		{ID: "refund1", PaymentID: "testPaymentID", Amount: 3000, Status: refunds.Completed},
+		{ID: "refund2", PaymentID: "testPaymentID", Amount: 5000, Status: refunds.Rejected},
+	}
+
+	// Create a test server
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var response interface{}
+
+		// Verify the request path
+		if r.URL.Path != "/payments/testPaymentID/refunds" {
+			t.Errorf("expected path to be /payments/testPaymentID/refunds, but got %s", r.URL.Path)
+		}
+
+		switch r.Method {
+		case http.MethodGet:
+			response = refunds.RefundsOutput{Data: existingRefunds}
+		case http.MethodPost:
+			// Verify the request body
+			var requestBody map[string]interface{}
+			err := json.NewDecoder(r.Body).Decode(&requestBody)
+			if err != nil {
+				t.Errorf("failed to decode the request body: %v", err)
+			}
+			if requestBody["endToEndId"] != "testEndToEndID" {
+				t.Errorf("expected endToEndId testEndToEndID, but got %v", requestBody["endToEndId"])
+			}
+
+			refund := refunds.RefundPayload{
+				ID:         "refund3",
+				PaymentID:  "testPaymentID",
+				EndToEndID: "testEndToEndID",
+				Amount:     requestBody["amount"].(float64),
+				Status:     refunds.Pending,
+			}
+			existingRefunds = append(existingRefunds, refund)
+			response = refund
+		default:
+			t.Errorf("unexpected method %s", r.Method)
+		}
+
+		responseBody, err := json.Marshal(response)
+		if err != nil {
+			t.Errorf("failed to encode the response: %v", err)
+		}
+
+		// Send the simulated response
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusOK)
+		_, _ = w.Write(responseBody)
+	}))
+	defer server.Close()
+
+	// Configure the authentication client for the test
+	authClient := auth.NewAuthClient("testClientID", "testClientSecret", "dev")
+	authClient.Environment = server.URL
+
+	// Set up a completed payment
+	status := payments.PaymentInitiationStatus(payments.PaymentCompleted)
+	payment := &payments.PaymentInitiationPayload{
+		ID:         "testPaymentID",
+		EndToEndID: "testEndToEndID",
+		Amount:     10000,
+		Status:     &status,
+	}
+
+	// Verify that refunding more than what is left is rejected locally
+	_, err := refunds.Create("testAccessToken", payment, &refunds.RefundInput{Amount: 7001}, authClient)
+	if err == nil {
+		t.Errorf("expected an error refunding more than the refundable amount")
+	}
+
+	// Execute a full refund of the remaining amount
+	refund, err := refunds.Create("testAccessToken", payment, &refunds.RefundInput{Reason: refunds.CustomerRequest}, authClient)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if refund.Amount != 7000 {
+		t.Errorf("expected refund amount 7000, actual refund amount %v", refund.Amount)
+	}
+
+	// Verify that the payment cannot be refunded again
+	_, err = refunds.Create("testAccessToken", payment, &refunds.RefundInput{}, authClient)
+	if err == nil {
+		t.Errorf("expected an error refunding a fully refunded payment")
+	}
+
+	// Verify that only completed payments can be refunded
+	pending := payments.PaymentInitiationStatus(payments.PaymentPending)
+	payment.Status = &pending
+	_, err = refunds.Create("testAccessToken", payment, &refunds.RefundInput{Amount: 100}, authClient)
+	if err == nil {
+		t.Errorf("expected an error refunding a pending payment")
+	}
+}
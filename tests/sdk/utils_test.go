@@ -0,0 +1,379 @@
+package sdk
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"iniciador-sdk/iniciador/auth"
+	"iniciador-sdk/iniciador/participants"
+	"iniciador-sdk/iniciador/payments"
+	"iniciador-sdk/iniciador/utils"
+	"iniciador-sdk/tests/sdk/helpers"
+)
+
+func TestTimestampJSON(t *testing.T) {
+	// Decode timestamps with and without offset
+	var payload payments.PaymentStatusPayload
+	err := json.Unmarshal([]byte(`{"id":"testID","date":"2024-02-29","createdAt":"2024-03-01T02:30:00.000Z","updatedAt":"2024-02-29T23:45:00"}`), &payload)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	expectedCreatedAt := time.Date(2024, time.March, 1, 2, 30, 0, 0, time.UTC)
+	if !payload.CreatedAt.Equal(expectedCreatedAt) {
+		t.Errorf("expected createdAt %s, actual createdAt %s", expectedCreatedAt, payload.CreatedAt)
+	}
+	expectedUpdatedAt := time.Date(2024, time.March, 1, 2, 45, 0, 0, time.UTC)
+	if !payload.UpdatedAt.Equal(expectedUpdatedAt) {
+		t.Errorf("expected updatedAt read as Brazil time %s, actual updatedAt %s", expectedUpdatedAt, payload.UpdatedAt.UTC())
+	}
+	if payload.Date != utils.NewDate(2024, time.February, 29) {
+		t.Errorf("expected date 2024-02-29, actual date %s", payload.Date)
+	}
+
+	// Verify the date boundary around midnight in São Paulo
+	if payload.CreatedAt.BrazilDate() != utils.NewDate(2024, time.February, 29) {
+		t.Errorf("expected Brazil date 2024-02-29, actual Brazil date %s", payload.CreatedAt.BrazilDate())
+	}
+
+	// Verify the round trip
+	encoded, err := json.Marshal(payload)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	var decoded payments.PaymentStatusPayload
+	err = json.Unmarshal(encoded, &decoded)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !decoded.CreatedAt.Equal(payload.CreatedAt.Time) || decoded.Date != payload.Date {
+		t.Errorf("expected %+v after round trip, got %+v", payload, decoded)
+	}
+
+	// Verify that unset fields are not sent
+	encoded, err = utils.MarshalWithoutEmptyFields(&payments.PaymentInitiationPayload{Amount: 100})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	var fields map[string]interface{}
+	_ = json.Unmarshal(encoded, &fields)
+	if _, ok := fields["createdAt"]; ok {
+		t.Errorf("expected createdAt to be omitted, got %s", encoded)
+	}
+	if _, ok := fields["date"]; ok {
+		t.Errorf("expected date to be omitted, got %s", encoded)
+	}
+}
+
+func TestDateFromTimestamp(t *testing.T) {
+	// Date fields sent as timestamps, with or without offset
+	testCases := []struct {
+		value    string
+		expected utils.Date
+	}{
+		{value: "2024-03-01T02:30:00Z", expected: utils.NewDate(2024, time.February, 29)},
+		{value: "2024-02-29T23:30:00", expected: utils.NewDate(2024, time.February, 29)},
+		{value: "2024-02-29 23:30:00", expected: utils.NewDate(2024, time.February, 29)},
+		{value: "2024-02-29T23:30:00.123", expected: utils.NewDate(2024, time.February, 29)},
+	}
+	for _, testCase := range testCases {
+		var date utils.Date
+		err := json.Unmarshal([]byte(`"`+testCase.value+`"`), &date)
+		if err != nil {
+			t.Errorf("%s: unexpected error: %v", testCase.value, err)
+			continue
+		}
+		if date != testCase.expected {
+			t.Errorf("%s: expected date %s, got %s", testCase.value, testCase.expected, date)
+		}
+	}
+}
+
+func TestBusinessDate(t *testing.T) {
+	testCases := []struct {
+		instant  time.Time
+		expected utils.Date
+	}{
+		// Thursday night in São Paulo, already Friday in UTC
+		{instant: time.Date(2024, time.March, 1, 2, 30, 0, 0, time.UTC), expected: utils.NewDate(2024, time.February, 29)},
+		// Saturday moves to Monday
+		{instant: time.Date(2024, time.March, 2, 15, 0, 0, 0, time.UTC), expected: utils.NewDate(2024, time.March, 4)},
+		// Carnival Monday and Tuesday move to Ash Wednesday
+		{instant: time.Date(2024, time.February, 12, 15, 0, 0, 0, time.UTC), expected: utils.NewDate(2024, time.February, 14)},
+		// Good Friday moves to Monday
+		{instant: time.Date(2024, time.March, 29, 15, 0, 0, 0, time.UTC), expected: utils.NewDate(2024, time.April, 1)},
+		// Christmas
+		{instant: time.Date(2024, time.December, 25, 15, 0, 0, 0, time.UTC), expected: utils.NewDate(2024, time.December, 26)},
+	}
+
+	for _, testCase := range testCases {
+		actual := utils.BusinessDate(testCase.instant)
+		if actual != testCase.expected {
+			t.Errorf("expected business date of %s to be %s, got %s", testCase.instant, testCase.expected, actual)
+		}
+	}
+
+	// Verify the business date of a payment created on a Sunday evening
+	payment := &payments.PaymentInitiationPayload{
+		CreatedAt: utils.NewTimestamp(time.Date(2024, time.March, 4, 1, 0, 0, 0, time.UTC)),
+	}
+	if payment.BusinessDate() != utils.NewDate(2024, time.March, 4) {
+		t.Errorf("expected payment business date 2024-03-04, got %s", payment.BusinessDate())
+	}
+}
+
+func TestMarshalWithoutEmptyFields(t *testing.T) {
+	pending := payments.PaymentInitiationStatus(payments.PaymentPending)
+	fee := 0.0
+
+	testCases := []struct {
+		name     string
+		payload  interface{}
+		fields   []string
+		expected string
+	}{
+		{
+			name: "nested empty fields",
+			payload: &payments.PaymentInitiationPayload{
+				Amount:   1000,
+				User:     payments.User{TaxID: "52998224725"},
+				Debtor:   &payments.BankAccount{Number: "123", Bank: &payments.Bank{}},
+				Metadata: payments.Metadata{},
+			},
+			expected: `{"amount":1000,"debtor":{"bank":{},"number":"123"},"user":{"taxId":"52998224725"}}`,
+		},
+		{
+			name: "pointers to zero values",
+			payload: &struct {
+				Status *payments.PaymentInitiationStatus
+				Fee    *float64
+			}{Status: &pending, Fee: &fee},
+			expected: `{"Fee":0,"Status":"PAYMENT_PENDING"}`,
+		},
+		{
+			name:     "masked zero and null fields",
+			payload:  &payments.PaymentInitiationPayload{Debtor: &payments.BankAccount{ISPB: "12345678"}},
+			fields:   []string{"amount", "creditor", "debtor.number"},
+			expected: `{"amount":0,"creditor":null,"debtor":{"ispb":"12345678","number":""}}`,
+		},
+		{
+			name:     "empty payload",
+			payload:  &payments.PaymentInitiationPayload{},
+			expected: `{}`,
+		},
+	}
+
+	for _, testCase := range testCases {
+		actual, err := utils.MarshalWithFields(testCase.payload, testCase.fields...)
+		if err != nil {
+			t.Errorf("%s: unexpected error: %v", testCase.name, err)
+			continue
+		}
+		if string(actual) != testCase.expected {
+			t.Errorf("%s: expected %s, actual %s", testCase.name, testCase.expected, actual)
+		}
+	}
+}
+
+func TestStrictDecoding(t *testing.T) {
+	// Create a test server returning a renamed field
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusOK)
+		_, _ = w.Write([]byte(`{"id":"testID","date":"2024-02-29","createdAt":"2024-02-29T10:00:00Z","updatedAt":"2024-02-29T10:00:00Z","amount":100,"paymentStatus":"PAYMENT_COMPLETED","error":{"code":"E1","description":"d","detail":"x"}}`))
+	}))
+	defer server.Close()
+
+	// Configure the authentication client for the test
+	authClient := auth.NewAuthClient("testClientID", "testClientSecret", "dev")
+	authClient.Environment = server.URL
+
+	// Enable strict decoding, collecting the reports
+	var reports []utils.DecodeReport
+	utils.EnableStrictDecoding(func(report utils.DecodeReport) {
+		reports = append(reports, report)
+	})
+	defer utils.DisableStrictDecoding()
+
+	// Execute the Status function
+	status, err := payments.Status(helpers.NewAccessToken("testID"), authClient)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	// Verify the report
+	expectedReports := []utils.DecodeReport{
+		{
+			Type:          "payments.PaymentStatusPayload",
+			UnknownFields: []string{"error.detail", "paymentStatus"},
+			MissingFields: []string{"externalId", "status"},
+		},
+	}
+	if !helpers.IsEqual(reports, expectedReports) {
+		t.Errorf("expected reports %+v, actual reports %+v", expectedReports, reports)
+	}
+
+	// Verify that the unknown fields are kept on the response
+	if string(status.Unknown()["paymentStatus"]) != `"PAYMENT_COMPLETED"` {
+		t.Errorf("expected unknown field paymentStatus to be kept, got %v", status.Unknown())
+	}
+
+	// Verify that response types stay comparable
+	participant := participants.ParticipantsPayload{ID: "testID"}
+	seen := map[participants.ParticipantsPayload]bool{participant: true}
+	if participant != (participants.ParticipantsPayload{ID: "testID"}) || !seen[participant] {
+		t.Errorf("expected equal participants to compare equal")
+	}
+
+	// Verify that nothing is reported once disabled
+	utils.DisableStrictDecoding()
+	_, err = payments.Status(helpers.NewAccessToken("testID"), authClient)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(reports) != 1 {
+		t.Errorf("expected no more reports, got %+v", reports)
+	}
+}
+
+func TestEndToEndID(t *testing.T) {
+	// Parse a valid EndToEndID
+	parsed, err := utils.ParseEndToEndID("E12345678202402292330abcdefghijk")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if parsed.ISPB != "12345678" || parsed.Sequence != "abcdefghijk" || !parsed.Timestamp.Equal(time.Date(2024, time.February, 29, 23, 30, 0, 0, time.UTC)) {
+		t.Errorf("unexpected parsed EndToEndID %+v", parsed)
+	}
+	if parsed.String() != "E12345678202402292330abcdefghijk" {
+		t.Errorf("expected the EndToEndID to round trip, got %s", parsed)
+	}
+
+	invalidIDs := []string{
+		"",
+		"E12345678202402292330abcdefghij",
+		"D12345678202402292330abcdefghijk",
+		"E1234567X202402292330abcdefghijk",
+		"E12345678202302292330abcdefghijk",
+		"E12345678202402292330abcdefghi-k",
+	}
+	for _, id := range invalidIDs {
+		if utils.IsValidEndToEndID(id) {
+			t.Errorf("expected %q to be invalid", id)
+		}
+	}
+
+	// Generate an EndToEndID in UTC
+	instant := time.Date(2024, time.March, 1, 9, 15, 42, 0, utils.BrazilLocation)
+	generated, err := utils.NewEndToEndID("87654321", instant)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	parsed, err = utils.ParseEndToEndID(generated)
+	if err != nil {
+		t.Fatalf("unexpected error for generated %s: %v", generated, err)
+	}
+	if parsed.ISPB != "87654321" || !parsed.Timestamp.Equal(instant.Truncate(time.Minute)) {
+		t.Errorf("unexpected generated EndToEndID %s", generated)
+	}
+}
+
+func TestTransactionIdentification(t *testing.T) {
+	dynamic, err := utils.NewTransactionIdentification(30)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	testCases := []struct {
+		method  utils.PaymentMethod
+		txid    string
+		isValid bool
+	}{
+		{method: utils.PixDynamicQRCode, txid: dynamic, isValid: true},
+		{method: utils.PixDynamicQRCode, txid: "short", isValid: false},
+		{method: utils.PixStaticQRCode, txid: "abc123", isValid: true},
+		{method: utils.PixStaticQRCode, txid: dynamic, isValid: false},
+		{method: utils.PixDict, txid: "abc-123", isValid: false},
+		{method: utils.PixDict, txid: "", isValid: true},
+	}
+	for _, testCase := range testCases {
+		err := utils.ValidateTransactionIdentification(testCase.method, testCase.txid)
+		if testCase.isValid != (err == nil) {
+			t.Errorf("%s %q: expected valid %v, got error %v", testCase.method, testCase.txid, testCase.isValid, err)
+		}
+	}
+}
+
+func TestTaxIDValidation(t *testing.T) {
+	testCases := []struct {
+		taxID   string
+		isValid bool
+	}{
+		{taxID: "52998224725", isValid: true},
+		{taxID: "529.982.247-25", isValid: true},
+		{taxID: "52998224724", isValid: false},
+		{taxID: "11111111111", isValid: false},
+		{taxID: "abc529.982.247-25xyz", isValid: false},
+		{taxID: "529 982 247 25", isValid: false},
+		{taxID: "529.98224725", isValid: false},
+		{taxID: "11222333000181", isValid: true},
+		{taxID: "11.222.333/0001-81", isValid: true},
+		{taxID: "11.222.333/0001-81 ", isValid: false},
+		{taxID: "CNPJ 11.222.333/0001-81", isValid: false},
+		// Alphanumeric CNPJs
+		{taxID: "12ABC34501DE35", isValid: true},
+		{taxID: "12.ABC.345/01DE-35", isValid: true},
+		{taxID: "12.ABC.345/01DE-36", isValid: false},
+		{taxID: "12.abc.345/01de-35", isValid: false},
+		{taxID: "12ABC34501DEAB", isValid: false},
+	}
+	for _, testCase := range testCases {
+		if utils.IsValidTaxID(testCase.taxID) != testCase.isValid {
+			t.Errorf("expected %q valid %v", testCase.taxID, testCase.isValid)
+		}
+	}
+
+	// Alphanumeric CNPJs are masked like numeric ones
+	if masked := utils.MaskTaxID("12.ABC.345/01DE-35"); masked != "**.ABC.345/01DE-**" {
+		t.Errorf("expected the CNPJ masked, got %s", masked)
+	}
+}
+
+func TestAPIErrorTimestamp(t *testing.T) {
+	// Create a test server answering an error with an unusual timestamp layout
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusUnprocessableEntity)
+		_, _ = w.Write([]byte(`{"errorCode":"INVALID_AMOUNT","message":["invalid amount"],"statusCode":422,"timestamp":"29/02/2024 23:30"}`))
+	}))
+	defer server.Close()
+
+	// Configure the authentication client for the test
+	authClient := auth.NewAuthClient("testClientID", "testClientSecret", "dev")
+	authClient.Environment = server.URL
+
+	// The API error is returned whatever the timestamp layout
+	_, err := payments.Status(helpers.NewAccessToken("testID"), authClient)
+	apiError, ok := err.(*utils.APIError)
+	if !ok {
+		t.Fatalf("expected an APIError, got %v", err)
+	}
+	if apiError.StatusCode != http.StatusUnprocessableEntity || apiError.Response.ErrorCode != "INVALID_AMOUNT" || apiError.Response.Timestamp != "29/02/2024 23:30" {
+		t.Errorf("unexpected API error %+v", apiError)
+	}
+	if _, err = apiError.Response.Time(); err == nil {
+		t.Errorf("expected an error parsing the unusual timestamp")
+	}
+
+	// The timestamp is parsed with the layouts of Timestamp
+	errorTime, err := utils.Error{Timestamp: "2024-02-29T23:30:00"}.Time()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !errorTime.Equal(time.Date(2024, time.March, 1, 2, 30, 0, 0, time.UTC)) {
+		t.Errorf("expected the timestamp in Brazil time, got %s", errorTime)
+	}
+}
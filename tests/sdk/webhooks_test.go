@@ -0,0 +1,322 @@
+package sdk
+
+import (
+	"bytes"
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+	"time"
+
+	"iniciador-sdk/iniciador/auth"
+	"iniciador-sdk/iniciador/payments"
+	"iniciador-sdk/iniciador/webhooks"
+)
+
+func signedNotification(secret []byte, timestamp time.Time, body []byte) *http.Request {
+	unix := strconv.FormatInt(timestamp.Unix(), 10)
+	req := httptest.NewRequest(http.MethodPost, "/webhooks", bytes.NewReader(body))
+	req.Header.Set(webhooks.DefaultTimestampHeader, unix)
+	req.Header.Set(webhooks.DefaultSignatureHeader, "sha256="+hex.EncodeToString(webhooks.Sign(secret, unix, body)))
+	return req
+}
+
+func TestWebhookReceiver(t *testing.T) {
+	secret := []byte("testSecret")
+	receiver := webhooks.NewReceiver(&webhooks.HMACVerifier{Secret: secret})
+
+	// Register handlers, failing the first completed event
+	var completed, all []string
+	shouldFail := true
+	receiver.On(func(ctx context.Context, event *webhooks.Event) error {
+		if shouldFail {
+			shouldFail = false
+			return errors.New("temporary failure")
+		}
+		completed = append(completed, event.Data.ID)
+		return nil
+	}, payments.PaymentCompleted)
+	receiver.OnAny(func(ctx context.Context, event *webhooks.Event) error {
+		all = append(all, event.ID)
+		return nil
+	})
+
+	deliver := func(req *http.Request) int {
+		recorder := httptest.NewRecorder()
+		receiver.ServeHTTP(recorder, req)
+		return recorder.Code
+	}
+	body, _ := json.Marshal(webhooks.Event{
+		ID:   "event1",
+		Type: webhooks.PaymentStatusChanged,
+		Data: payments.PaymentStatusPayload{ID: "testPaymentID", Status: string(payments.PaymentCompleted)},
+	})
+
+	// A failing handler makes the sender retry
+	if code := deliver(signedNotification(secret, time.Now(), body)); code != http.StatusInternalServerError {
+		t.Errorf("expected status 500 on handler failure, got %d", code)
+	}
+	// The retry is processed
+	if code := deliver(signedNotification(secret, time.Now(), body)); code != http.StatusOK {
+		t.Errorf("expected status 200 on retry, got %d", code)
+	}
+	// A duplicate is acknowledged without processing
+	if code := deliver(signedNotification(secret, time.Now(), body)); code != http.StatusOK {
+		t.Errorf("expected status 200 for a duplicate, got %d", code)
+	}
+	if !(len(completed) == 1 && completed[0] == "testPaymentID") || len(all) != 1 {
+		t.Errorf("expected the event to be processed once, got completed %v and all %v", completed, all)
+	}
+
+	// Events of other statuses only reach the generic handlers
+	rejected, _ := json.Marshal(webhooks.Event{ID: "event2", Data: payments.PaymentStatusPayload{ID: "testPaymentID", Status: string(payments.PaymentRejected)}})
+	if code := deliver(signedNotification(secret, time.Now(), rejected)); code != http.StatusOK || len(completed) != 1 || len(all) != 2 {
+		t.Errorf("unexpected dispatch of a rejected event: status %d, completed %v, all %v", code, completed, all)
+	}
+
+	// Tampered, wrongly signed and old notifications are refused
+	tampered := signedNotification(secret, time.Now(), body)
+	tampered.Body = httptest.NewRequest(http.MethodPost, "/", bytes.NewReader(rejected)).Body
+	if code := deliver(tampered); code != http.StatusUnauthorized {
+		t.Errorf("expected status 401 for a tampered body, got %d", code)
+	}
+	if code := deliver(signedNotification([]byte("otherSecret"), time.Now(), body)); code != http.StatusUnauthorized {
+		t.Errorf("expected status 401 for another secret, got %d", code)
+	}
+	if code := deliver(signedNotification(secret, time.Now().Add(-time.Hour), body)); code != http.StatusUnauthorized {
+		t.Errorf("expected status 401 for an old notification, got %d", code)
+	}
+}
+
+func TestWebhookReceiverPanic(t *testing.T) {
+	secret := []byte("testSecret")
+	receiver := webhooks.NewReceiver(&webhooks.HMACVerifier{Secret: secret})
+
+	// Register a handler panicking on the first call
+	calls := 0
+	receiver.OnAny(func(ctx context.Context, event *webhooks.Event) error {
+		calls++
+		if calls == 1 {
+			panic("unexpected state")
+		}
+		return nil
+	})
+
+	body, _ := json.Marshal(webhooks.Event{ID: "event1", Data: payments.PaymentStatusPayload{ID: "testPaymentID", Status: string(payments.PaymentCompleted)}})
+
+	// The panic is answered with 500 and the claim released
+	recorder := httptest.NewRecorder()
+	receiver.ServeHTTP(recorder, signedNotification(secret, time.Now(), body))
+	if recorder.Code != http.StatusInternalServerError {
+		t.Errorf("expected status 500 on handler panic, got %d", recorder.Code)
+	}
+
+	// The retry is processed
+	recorder = httptest.NewRecorder()
+	receiver.ServeHTTP(recorder, signedNotification(secret, time.Now(), body))
+	if recorder.Code != http.StatusOK || calls != 2 {
+		t.Errorf("expected the retry to be processed, got status %d after %d calls", recorder.Code, calls)
+	}
+}
+
+func TestWebhookHMACVerifierWithoutSecret(t *testing.T) {
+	// A notification signed with the empty key is refused
+	receiver := webhooks.NewReceiver(&webhooks.HMACVerifier{})
+	receiver.OnAny(func(ctx context.Context, event *webhooks.Event) error {
+		t.Errorf("unexpected event %+v", event)
+		return nil
+	})
+
+	body, _ := json.Marshal(webhooks.Event{ID: "event1", Data: payments.PaymentStatusPayload{ID: "testPaymentID", Status: string(payments.PaymentCompleted)}})
+	recorder := httptest.NewRecorder()
+	receiver.ServeHTTP(recorder, signedNotification(nil, time.Now(), body))
+	if recorder.Code != http.StatusUnauthorized {
+		t.Errorf("expected status 401 without a secret, got %d", recorder.Code)
+	}
+}
+
+func TestWebhookJWTVerifier(t *testing.T) {
+	privateKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	sign := func(alg string, claims map[string]interface{}) []byte {
+		header, _ := json.Marshal(map[string]string{"alg": alg, "typ": "JWT"})
+		payload, _ := json.Marshal(claims)
+		signed := base64.RawURLEncoding.EncodeToString(header) + "." + base64.RawURLEncoding.EncodeToString(payload)
+		digest := sha256.Sum256([]byte(signed))
+		r, s, _ := ecdsa.Sign(rand.Reader, privateKey, digest[:])
+		signature := make([]byte, 64)
+		r.FillBytes(signature[:32])
+		s.FillBytes(signature[32:])
+		return []byte(signed + "." + base64.RawURLEncoding.EncodeToString(signature))
+	}
+
+	var received *webhooks.Event
+	receiver := webhooks.NewReceiver(&webhooks.JWTVerifier{PublicKey: &privateKey.PublicKey, Audience: "testClientID"})
+	receiver.OnAny(func(ctx context.Context, event *webhooks.Event) error {
+		received = event
+		return nil
+	})
+
+	claims := map[string]interface{}{
+		"id":   "event1",
+		"type": webhooks.PaymentStatusChanged,
+		"aud":  []string{"testClientID"},
+		"exp":  time.Now().Add(time.Minute).Unix(),
+		"data": map[string]interface{}{"id": "testPaymentID", "status": payments.PaymentCompleted},
+	}
+	recorder := httptest.NewRecorder()
+	receiver.ServeHTTP(recorder, httptest.NewRequest(http.MethodPost, "/webhooks", bytes.NewReader(sign("ES256", claims))))
+	if recorder.Code != http.StatusOK || received == nil || received.Status() != payments.PaymentCompleted {
+		t.Errorf("expected the event to be received, got status %d and event %+v", recorder.Code, received)
+	}
+
+	// Expired tokens and mismatched algorithms are refused
+	claims["id"], claims["exp"] = "event2", time.Now().Add(-time.Hour).Unix()
+	recorder = httptest.NewRecorder()
+	receiver.ServeHTTP(recorder, httptest.NewRequest(http.MethodPost, "/webhooks", bytes.NewReader(sign("ES256", claims))))
+	if recorder.Code != http.StatusUnauthorized {
+		t.Errorf("expected status 401 for an expired token, got %d", recorder.Code)
+	}
+	claims["exp"] = time.Now().Add(time.Minute).Unix()
+	recorder = httptest.NewRecorder()
+	receiver.ServeHTTP(recorder, httptest.NewRequest(http.MethodPost, "/webhooks", bytes.NewReader(sign("HS256", claims))))
+	if recorder.Code != http.StatusUnauthorized {
+		t.Errorf("expected status 401 for a mismatched algorithm, got %d", recorder.Code)
+	}
+}
+
+func TestWebhookSubscriptions(t *testing.T) {
+	subscription := webhooks.Subscription{
+		ID:     "testSubscriptionID",
+		URL:    "https://example.com/webhooks",
+		Events: []string{webhooks.PaymentStatusChanged},
+		Status: webhooks.Active,
+	}
+	isDeleted := false
+
+	// Create a test server
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var response interface{}
+		var requestBody map[string]interface{}
+		if r.Body != nil {
+			_ = json.NewDecoder(r.Body).Decode(&requestBody)
+		}
+
+		// Verify the request method and path
+		switch {
+		case r.Method == http.MethodPost && r.URL.Path == "/webhooks":
+			if requestBody["url"] != subscription.URL {
+				t.Errorf("unexpected create request body %v", requestBody)
+			}
+			created := subscription
+			created.Secret = "secret1"
+			response = created
+		case r.Method == http.MethodGet && r.URL.Path == "/webhooks":
+			response = webhooks.SubscriptionsOutput{Data: []webhooks.Subscription{subscription}}
+		case r.Method == http.MethodPatch && r.URL.Path == "/webhooks/testSubscriptionID":
+			if _, ok := requestBody["url"]; ok || requestBody["status"] != string(webhooks.Inactive) {
+				t.Errorf("expected only the status to be updated, got %v", requestBody)
+			}
+			subscription.Status = webhooks.Inactive
+			response = subscription
+		case r.Method == http.MethodPost && r.URL.Path == "/webhooks/testSubscriptionID/test":
+			if requestBody["event"] != webhooks.TestEvent {
+				t.Errorf("unexpected test request body %v", requestBody)
+			}
+			response = webhooks.TestResult{EventID: "testEventID", StatusCode: http.StatusOK, Success: true}
+		case r.Method == http.MethodPost && r.URL.Path == "/webhooks/testSubscriptionID/rotate-secret":
+			rotated := subscription
+			rotated.Secret = "secret2"
+			response = rotated
+		case r.Method == http.MethodDelete && r.URL.Path == "/webhooks/testSubscriptionID":
+			isDeleted = true
+			w.WriteHeader(http.StatusNoContent)
+			return
+		default:
+			t.Errorf("unexpected request %s %s", r.Method, r.URL.Path)
+		}
+
+		// Send the simulated response
+		responseBody, _ := json.Marshal(response)
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusOK)
+		_, _ = w.Write(responseBody)
+	}))
+	defer server.Close()
+
+	// Configure the authentication client for the test
+	authClient := auth.NewAuthClient("testClientID", "testClientSecret", "dev")
+	authClient.Environment = server.URL
+
+	// Verify that invalid subscriptions are not sent
+	_, err := webhooks.CreateSubscription("testAccessToken", &webhooks.SubscriptionInput{URL: "example.com/webhooks", Events: []string{webhooks.PaymentStatusChanged}}, authClient)
+	if err == nil {
+		t.Errorf("expected an error for a relative URL")
+	}
+
+	created, err := webhooks.CreateSubscription("testAccessToken", &webhooks.SubscriptionInput{URL: subscription.URL, Events: []string{webhooks.PaymentStatusChanged}}, authClient)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if created.Secret != "secret1" {
+		t.Errorf("expected the secret to be returned, got %+v", created)
+	}
+
+	output, err := webhooks.ListSubscriptions("testAccessToken", authClient)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(output.Data) != 1 || output.Data[0].Secret != "" {
+		t.Errorf("unexpected subscriptions %+v", output.Data)
+	}
+
+	updated, err := webhooks.UpdateSubscription("testAccessToken", "testSubscriptionID", &webhooks.SubscriptionInput{Status: webhooks.Inactive}, authClient)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if updated.Status != webhooks.Inactive {
+		t.Errorf("expected status %s, got %s", webhooks.Inactive, updated.Status)
+	}
+
+	result, err := webhooks.TestSubscription("testAccessToken", "testSubscriptionID", authClient)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !result.Success {
+		t.Errorf("expected a successful test, got %+v", result)
+	}
+
+	// Verify that both secrets are accepted while rotating
+	rotated, err := webhooks.RotateSecret("testAccessToken", "testSubscriptionID", authClient)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	verifier := &webhooks.HMACVerifier{Secret: []byte(rotated.Secret), PreviousSecret: []byte(created.Secret)}
+	for _, secret := range []string{rotated.Secret, created.Secret} {
+		body := []byte(`{"id":"event"}`)
+		_, err = verifier.Verify(signedNotification([]byte(secret), time.Now(), body), body)
+		if err != nil {
+			t.Errorf("expected notifications signed with %s to be accepted, got %v", secret, err)
+		}
+	}
+
+	err = webhooks.DeleteSubscription("testAccessToken", "testSubscriptionID", authClient)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !isDeleted {
+		t.Errorf("expected the subscription to be deleted")
+	}
+}